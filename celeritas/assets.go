@@ -0,0 +1,186 @@
+package celeritas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// assetManifest is the file, inside public, which maps each asset to its
+// fingerprinted name
+const assetManifest = "assets.json"
+
+// BuildAssets hashes every file in the public folder under the
+// application root, before it is embedded, and writes the manifest,
+// public/assets.json, which maps each file to a name carrying its content
+// hash, e.g. css/app.css to css/app.3f9a2c1d.css. Nothing is copied: the
+// file server maps fingerprinted names back to the files.
+func (c *Celeritas) BuildAssets() (map[string]string, error) {
+	root := filepath.Join(c.RootPath, "public")
+	manifest := make(map[string]string)
+
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && file != root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		name, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if name == assetManifest {
+			return nil
+		}
+
+		sum, err := hashFile(file)
+		if err != nil {
+			return err
+		}
+		manifest[name] = fingerprint(name, sum)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(root, assetManifest), out, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// hashFile returns the first eight hex digits of the file's sha256
+func hashFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8], nil
+}
+
+// fingerprint puts the hash in front of the extension
+func fingerprint(name, sum string) string {
+	ext := path.Ext(name)
+	if ext == "" || strings.HasPrefix(path.Base(name), ext) {
+		return name + "." + sum
+	}
+	return strings.TrimSuffix(name, ext) + "." + sum + ext
+}
+
+// Asset returns the URL of a file in public, using its fingerprinted name
+// from public/assets.json so that browsers can cache it for ever. In debug
+// mode, or when the file is not in the manifest, the plain path is used.
+// Templates call it as asset("css/app.css").
+func (c *Celeritas) Asset(name string) string {
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "/"), "public/")
+	if c.Debug {
+		return "/public/" + name
+	}
+
+	c.loadAssets()
+	if hashed, ok := c.assets[name]; ok {
+		return "/public/" + hashed
+	}
+	return "/public/" + name
+}
+
+// publicFS returns c.Public, which may be embedded in the binary, or the
+// public folder when it is not set
+func (c *Celeritas) publicFS() fs.FS {
+	if c.Public != nil {
+		return c.Public
+	}
+	return os.DirFS(filepath.Join(c.RootPath, "public"))
+}
+
+// assetSource maps a fingerprinted name, relative to public, back to the
+// file it was built from
+func (c *Celeritas) assetSource(name string) (string, bool) {
+	c.loadAssets()
+	source, ok := c.assetSources[name]
+	return source, ok
+}
+
+// assetCurrent reports whether the fingerprinted name matches the current
+// contents of source. The hash is worked out again only when the file's
+// modification time or size changes.
+func (c *Celeritas) assetCurrent(root http.FileSystem, name, source string) bool {
+	f, err := root.Open("/" + source)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	key := fmt.Sprintf("%s %x %x", source, info.ModTime().UnixNano(), info.Size())
+	if sum, ok := c.assetSums.Load(key); ok {
+		return fingerprint(source, sum.(string)) == name
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return false
+	}
+	sum := hex.EncodeToString(h.Sum(nil))[:8]
+	c.assetSums.Store(key, sum)
+
+	return fingerprint(source, sum) == name
+}
+
+// loadAssets reads the manifest once. A missing manifest, e.g. before
+// celeritas assets:build has been run, leaves every path as it is.
+func (c *Celeritas) loadAssets() {
+	c.assetsOnce.Do(func() {
+		c.assets = make(map[string]string)
+		c.assetSources = make(map[string]string)
+
+		data, err := fs.ReadFile(c.publicFS(), assetManifest)
+		if err != nil {
+			return
+		}
+		if err = json.Unmarshal(data, &c.assets); err != nil {
+			c.Logger.Warn("unreadable asset manifest", "error", err)
+			return
+		}
+
+		for name, hashed := range c.assets {
+			c.assetSources[hashed] = name
+		}
+	})
+}
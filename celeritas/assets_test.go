@@ -0,0 +1,87 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCeleritas_Assets(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "public", "css"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "public", "css", "app.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Celeritas{RootPath: root}
+	manifest, err := c.BuildAssets()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed := manifest["css/app.css"]
+	sum, err := hashFile(filepath.Join(root, "public", "css", "app.css"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed != "css/app."+sum+".css" {
+		t.Fatalf("unexpected fingerprinted name %q", hashed)
+	}
+	if _, err := os.Stat(filepath.Join(root, "public", "assets.json")); err != nil {
+		t.Error("manifest was not written")
+	}
+
+	if got := c.Asset("css/app.css"); got != "/public/"+hashed {
+		t.Errorf("expected /public/%s, got %s", hashed, got)
+	}
+	if got := c.Asset("/public/js/missing.js"); got != "/public/js/missing.js" {
+		t.Errorf("expected an unknown asset to keep its path, got %s", got)
+	}
+
+	debug := &Celeritas{RootPath: root, Debug: true}
+	if got := debug.Asset("css/app.css"); got != "/public/css/app.css" {
+		t.Errorf("expected the plain path in debug mode, got %s", got)
+	}
+
+	handler := http.StripPrefix("/public", c.FileServer(http.Dir(filepath.Join(root, "public"))))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/public/"+hashed, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "body{}" {
+		t.Fatalf("expected the fingerprinted name to be served, got %d", w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); !strings.Contains(got, "immutable") {
+		t.Errorf("expected immutable caching, got %q", got)
+	}
+
+	// the file changes after the manifest was built
+	if err := os.WriteFile(filepath.Join(root, "public", "css", "app.css"), []byte("body{color:red}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/public/"+hashed, nil))
+	if w.Code != http.StatusNotFound || strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("expected a stale fingerprint to be 404, got %d %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expected string
+	}{
+		{"css/app.css", "css/app.abcd1234.css"},
+		{"LICENSE", "LICENSE.abcd1234"},
+		{".htaccess", ".htaccess.abcd1234"},
+	}
+
+	for _, e := range tests {
+		if got := fingerprint(e.name, "abcd1234"); got != e.expected {
+			t.Errorf("%s: expected %s, got %s", e.name, e.expected, got)
+		}
+	}
+}
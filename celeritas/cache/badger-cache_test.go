@@ -1,6 +1,9 @@
 package cache
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestBadgerCache_Has(t *testing.T) {
 	err := testBadgerCache.Forget("foo")
@@ -137,3 +140,47 @@ func TestBadgerCache_EmptyByMatch(t *testing.T) {
 		t.Error("beta not found in cache, and it should be there")
 	}
 }
+
+func TestBadgerCache_Increment(t *testing.T) {
+	_ = testBadgerCache.Forget("hits")
+
+	for i := int64(1); i <= 3; i++ {
+		count, err := testBadgerCache.Increment("hits", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != i {
+			t.Errorf("expected count %d, got %d", i, count)
+		}
+	}
+
+	count, err := testBadgerCache.Count("hits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected count 3, got %d", count)
+	}
+}
+
+func TestBadgerCache_Stats(t *testing.T) {
+	before := testBadgerCache.Stats()
+
+	err := testBadgerCache.Set("foo", "bar")
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, _ = testBadgerCache.Has("foo")
+	_, _ = testBadgerCache.Has("missing")
+	_, _ = testBadgerCache.Get("foo")
+	_, _ = testBadgerCache.Get("missing")
+
+	after := testBadgerCache.Stats()
+	if after.Hits != before.Hits+2 {
+		t.Errorf("expected two more hits, got %d then %d", before.Hits, after.Hits)
+	}
+	if after.Misses != before.Misses+2 {
+		t.Errorf("expected two more misses, got %d then %d", before.Misses, after.Misses)
+	}
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"errors"
 	"time"
 
 	"github.com/dgraph-io/badger/v3"
@@ -9,13 +10,28 @@ import (
 type BadgerCache struct {
 	Conn   *badger.DB
 	Prefix string
+	counts counters
+}
+
+// Stats returns the number of lookups which found, or missed, their key
+func (b *BadgerCache) Stats() Stats {
+	return b.counts.stats()
 }
 
 func (b *BadgerCache) Has(str string) (bool, error) {
-	_, err := b.Get(str)
-	if err != nil {
+	err := b.Conn.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(str))
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		b.counts.record(false)
 		return false, nil
 	}
+	if err != nil {
+		return false, err
+	}
+
+	b.counts.record(true)
 	return true, nil
 }
 
@@ -37,9 +53,13 @@ func (b *BadgerCache) Get(str string) (interface{}, error) {
 		return nil
 	})
 
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		b.counts.record(false)
+	}
 	if err != nil {
 		return nil, err
 	}
+	b.counts.record(true)
 
 	decoded, err := decode(string(fromCache))
 	if err != nil {
@@ -3,6 +3,7 @@ package cache
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
 	"fmt"
 
 	"github.com/gomodule/redigo/redis"
@@ -20,6 +21,7 @@ type Cache interface {
 type RedisCache struct {
 	Conn   *redis.Pool
 	Prefix string
+	counts counters
 }
 
 type Entry map[string]interface{}
@@ -34,9 +36,15 @@ func (c *RedisCache) Has(str string) (bool, error) {
 		return false, err
 	}
 
+	c.counts.record(ok)
 	return ok, nil
 }
 
+// Stats returns the number of lookups which found, or missed, their key
+func (c *RedisCache) Stats() Stats {
+	return c.counts.stats()
+}
+
 func encode(item Entry) ([]byte, error) {
 	b := bytes.Buffer{}
 	e := gob.NewEncoder(&b)
@@ -65,9 +73,13 @@ func (c *RedisCache) Get(str string) (interface{}, error) {
 	defer conn.Close()
 
 	cacheEntry, err := redis.Bytes(conn.Do("GET", key))
+	if errors.Is(err, redis.ErrNil) {
+		c.counts.record(false)
+	}
 	if err != nil {
 		return nil, err
 	}
+	c.counts.record(true)
 
 	decoded, err := decode(string(cacheEntry))
 	if err != nil {
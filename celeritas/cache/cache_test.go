@@ -1,6 +1,11 @@
 package cache
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
 
 func TestRedisCache_Has(t *testing.T) {
 	err := testRedisCache.Forget("foo")
@@ -159,3 +164,70 @@ func TestEncodeDecode(t *testing.T) {
 	}
 
 }
+
+func TestRedisCache_Stats(t *testing.T) {
+	before := testRedisCache.Stats()
+
+	err := testRedisCache.Set("foo", "bar")
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, _ = testRedisCache.Has("foo")
+	_, _ = testRedisCache.Has("missing")
+	_, _ = testRedisCache.Get("foo")
+	_, _ = testRedisCache.Get("missing")
+
+	after := testRedisCache.Stats()
+	if after.Hits != before.Hits+2 {
+		t.Errorf("expected two more hits, got %d then %d", before.Hits, after.Hits)
+	}
+	if after.Misses != before.Misses+2 {
+		t.Errorf("expected two more misses, got %d then %d", before.Misses, after.Misses)
+	}
+}
+
+func TestRedisCache_Increment(t *testing.T) {
+	_ = testRedisCache.Forget("hits")
+
+	for i := int64(1); i <= 3; i++ {
+		count, err := testRedisCache.Increment("hits", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != i {
+			t.Errorf("expected count %d, got %d", i, count)
+		}
+	}
+
+	count, err := testRedisCache.Count("hits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("expected count 3, got %d", count)
+	}
+
+	// a counter left without a time to live gets one on the next increment
+	_ = testRedisCache.Forget("stuck")
+	conn := testRedisCache.Conn.Get()
+	_, err = conn.Do("SET", testRedisCache.Prefix+":stuck", 5)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, err = testRedisCache.Increment("stuck", time.Minute); err != nil || count != 6 {
+		t.Fatalf("expected count 6, got %d, %v", count, err)
+	}
+	conn = testRedisCache.Conn.Get()
+	ttl, err := redis.Int64(conn.Do("PTTL", testRedisCache.Prefix+":stuck"))
+	conn.Close()
+	if err != nil || ttl <= 0 || ttl > time.Minute.Milliseconds() {
+		t.Errorf("expected the counter to get a ttl of a minute, got %dms, %v", ttl, err)
+	}
+
+	count, err = testRedisCache.Count("no-hits")
+	if err != nil || count != 0 {
+		t.Errorf("expected a missing counter to be 0, got %d, %v", count, err)
+	}
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Counter is implemented by caches which can keep atomic counters, such
+// as the ones used for rate limiting. Counters are stored as plain
+// integers, so use keys which are not also used with Get and Set.
+type Counter interface {
+	// Increment adds one to the counter at key, creating it with the given
+	// time to live if it does not exist, and returns the new count
+	Increment(key string, ttl time.Duration) (int64, error)
+
+	// Count returns the counter at key, or zero if it does not exist
+	Count(key string) (int64, error)
+}
+
+// incrementScript increments a counter and gives it a time to live when
+// it has none, in one step, so that a counter can never be left without
+// one to block a client for ever
+var incrementScript = redis.NewScript(1, `
+local count = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+func (c *RedisCache) Increment(str string, ttl time.Duration) (int64, error) {
+	key := fmt.Sprintf("%s:%s", c.Prefix, str)
+	conn := c.Conn.Get()
+	defer conn.Close()
+
+	return redis.Int64(incrementScript.Do(conn, key, ttl.Milliseconds()))
+}
+
+func (c *RedisCache) Count(str string) (int64, error) {
+	key := fmt.Sprintf("%s:%s", c.Prefix, str)
+	conn := c.Conn.Get()
+	defer conn.Close()
+
+	count, err := redis.Int64(conn.Do("GET", key))
+	if errors.Is(err, redis.ErrNil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+func (b *BadgerCache) Increment(str string, ttl time.Duration) (int64, error) {
+	var count int64
+
+	for {
+		err := b.Conn.Update(func(txn *badger.Txn) error {
+			count = 0
+			expiresAt := time.Now().Add(ttl)
+
+			item, err := txn.Get([]byte(str))
+			switch {
+			case errors.Is(err, badger.ErrKeyNotFound):
+			case err != nil:
+				return err
+			default:
+				err = item.Value(func(val []byte) error {
+					count, err = strconv.ParseInt(string(val), 10, 64)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				if item.ExpiresAt() > 0 {
+					expiresAt = time.Unix(int64(item.ExpiresAt()), 0)
+				}
+			}
+
+			count++
+			e := badger.NewEntry([]byte(str), []byte(strconv.FormatInt(count, 10))).WithTTL(time.Until(expiresAt))
+			return txn.SetEntry(e)
+		})
+
+		// another request incremented the counter at the same time
+		if errors.Is(err, badger.ErrConflict) {
+			continue
+		}
+		return count, err
+	}
+}
+
+func (b *BadgerCache) Count(str string) (int64, error) {
+	var count int64
+
+	err := b.Conn.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(str))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			count, err = strconv.ParseInt(string(val), 10, 64)
+			return err
+		})
+	})
+
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return count, err
+}
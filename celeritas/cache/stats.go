@@ -0,0 +1,31 @@
+package cache
+
+import "sync/atomic"
+
+// Stats counts cache lookups which found, or did not find, their key
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// StatsReporter is implemented by caches which count their hits and misses
+type StatsReporter interface {
+	Stats() Stats
+}
+
+type counters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *counters) record(found bool) {
+	if found {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *counters) stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
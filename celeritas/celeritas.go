@@ -1,11 +1,18 @@
 package celeritas
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"html/template"
+	"io/fs"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/CloudyKit/jet/v6"
@@ -13,24 +20,21 @@ import (
 	"github.com/dgraph-io/badger/v3"
 	"github.com/go-chi/chi/v5"
 	"github.com/gomodule/redigo/redis"
-	"github.com/joho/godotenv"
 	"github.com/leetrent/celeritas/cache"
+	"github.com/leetrent/celeritas/logging"
 	"github.com/leetrent/celeritas/render"
 	"github.com/leetrent/celeritas/session"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/robfig/cron/v3"
 )
 
 const version = "1.0.0"
 
-var myRedisCache *cache.RedisCache
-var myBadgerCache *cache.BadgerCache
-var redisPool *redis.Pool
-var badgerConn *badger.DB
-
 type Celeritas struct {
 	AppName       string
 	Debug         bool
 	Version       string
+	Logger        *slog.Logger
 	ErrorLog      *log.Logger
 	InfoLog       *log.Logger
 	RootPath      string
@@ -39,36 +43,60 @@ type Celeritas struct {
 	Session       *scs.SessionManager
 	DB            Database
 	JetViews      *jet.Set
-	config        config
+	Config        Config
 	EncryptionKey string
 	Cache         cache.Cache
 	Scheduler     *cron.Cron
-}
 
-type config struct {
-	port        string
-	renderer    string
-	cookie      cookieConfig
-	sessionType string
-	database    databaseConfig
-	redis       redisConig
+	// Public and Migrations hold the static files and the migrations,
+	// either embedded in the binary (see WithFS) or read from the
+	// folders under RootPath.
+	Public     fs.FS
+	Migrations fs.FS
+
+	// Metrics is the Prometheus registry served at METRICS_PATH when
+	// METRICS_ENABLED is true; it is nil otherwise.
+	Metrics *prometheus.Registry
+
+	// ShutdownTimeout is how long ListenAndServe waits for in-flight
+	// requests and shutdown hooks before giving up.
+	ShutdownTimeout time.Duration
+
+	logFile        *logging.RotatingFile
+	redisPool      *redis.Pool
+	badgerConn     *badger.DB
+	ownsDB         bool
+	ownsRedis      bool
+	ownsBadger     bool
+	server         *http.Server
+	redirectServer *http.Server
+	shutdownMu     sync.Mutex
+	shutdownHooks  []ShutdownHook
+	shutdownDone   bool
+	healthMu       sync.Mutex
+	healthChecks   []namedHealthCheck
+	httpMetrics    *httpMetrics
+	rateLimitOnce  sync.Once
+	memoryCounter  *memoryCounter
+	assetsOnce     sync.Once
+	assets         map[string]string
+	assetSources   map[string]string
+	assetSums      sync.Map
+	fileETags      sync.Map
+	maintenance    maintenanceState
+	routeNamesMu   sync.RWMutex
+	routeNames     map[string]string
 }
 
-func (c *Celeritas) New(rootPath string) error {
+// New initializes a celeritas application rooted at rootPath. It creates
+// the standard application folders and an empty .env file if they don't
+// exist yet, and reads its configuration from .env and the environment.
+// Options such as WithFS may be given as for NewApp. Use NewApp to build
+// an application without touching the file system.
+func (c *Celeritas) New(rootPath string, opts ...Option) error {
 	// logSnippet := "\n[celeritas][New] =>"
 	// fmt.Printf("%s (rootPath)..: %s\n", logSnippet, rootPath)
 
-	//////////////////////////////////////////////////////////
-	// ASSIGN APPLICATION ROOT PATH
-	//////////////////////////////////////////////////////////
-	c.RootPath = rootPath
-	//fmt.Printf("%s (c.RootPath): %s\n", logSnippet, c.RootPath)
-
-	//////////////////////////////////////////////////////////
-	// ASSIGN APPLICATION VERSION
-	//////////////////////////////////////////////////////////
-	c.Version = version
-
 	pathConfig := initPaths{
 		rootPath:    rootPath,
 		folderNames: []string{"handlers", "migrations", "views", "data", "public", "tmp", "logs", "middleware"},
@@ -91,185 +119,266 @@ func (c *Celeritas) New(rootPath string) error {
 	}
 
 	//////////////////////////////////////////////////////////
-	// Read contents of .env file and create an
-	// environment variable for each entry in .env file
+	// Read .env (and .env.<APP_ENV>) into the environment
+	// and build the typed configuration from it
 	//////////////////////////////////////////////////////////
-	//err = godotenv.Load(rootPath + "/.env")
-	err = godotenv.Load(rootPath + "\\.env")
+	cfg, err := LoadConfig(rootPath)
 	if err != nil {
 		return err
 	}
-	//fmt.Printf("%s (rootPath+\"\\.env\"): %s\n", logSnippet, rootPath+"\\.env")
 
-	//////////////////////////////////////////////////////////
-	// CREATE LOGGERS
-	//////////////////////////////////////////////////////////
-	infoLog, errorLog := c.startLoggers()
-	c.InfoLog = infoLog
-	c.ErrorLog = errorLog
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.rootPath = rootPath
+	o.config = cfg
 
-	//////////////////////////////////////////////////////////
-	// CONNECT TO DATABASE
-	//////////////////////////////////////////////////////////
-	if os.Getenv("DATABASE_TYPE") != "" {
-		db, err := c.OpenDB(os.Getenv("DATABASE_TYPE"), c.BuildDSN())
-		if err != nil {
-			c.ErrorLog.Println(err)
-			os.Exit(1)
-		}
-		c.DB = Database{
-			DataType: os.Getenv("DATABASE_TYPE"),
-			Pool:     db,
+	return c.setup(o)
+}
+
+// appFS returns the embedded file system for folder, or the folder under
+// the application root when nothing was embedded. In debug mode the folder
+// on disk wins, when it exists, so that edits show up straight away.
+func (c *Celeritas) appFS(embedded fs.FS, folder string) fs.FS {
+	dir := filepath.Join(c.RootPath, folder)
+	if embedded != nil {
+		if info, err := os.Stat(dir); !c.Debug || err != nil || !info.IsDir() {
+			return embedded
 		}
 	}
+	return os.DirFS(dir)
+}
 
+// setup wires up everything an application needs from its options,
+// creating whatever (database, cache, session manager, views) was not
+// supplied ready-made
+func (c *Celeritas) setup(o *options) error {
 	//////////////////////////////////////////////////////////
-	// CONNECT TO REDIS CACHE
+	// ASSIGN APPLICATION ROOT PATH, VERSION AND CONFIGURATION
 	//////////////////////////////////////////////////////////
-	if os.Getenv("CACHE") == "redis" || os.Getenv("SESSION_TYPE") == "redis" {
-		myRedisCache = c.createClientRedisCache()
-		c.Cache = myRedisCache
-		redisPool = myRedisCache.Conn
+	c.RootPath = o.rootPath
+	c.Version = version
+	c.Config = *o.config
+	if c.Config.Sections == nil {
+		c.Config.Sections = make(map[string]interface{})
 	}
 
 	//////////////////////////////////////////////////////////
-	// CONNECT TO BADGER CACHE
+	// CREATE LOGGERS
 	//////////////////////////////////////////////////////////
-	scheduler := cron.New()
-	c.Scheduler = scheduler
-	if os.Getenv("CACHE") == "badger" {
-		myBadgerCache = c.createClientBadgerCache()
-		c.Cache = myBadgerCache
-		badgerConn = myBadgerCache.Conn
-
-		_, err = c.Scheduler.AddFunc("@daily", func() {
-			_ = myBadgerCache.Conn.RunValueLogGC(0.7)
-		})
-		if err != nil {
-			return err
-		}
+	err := c.startLoggers()
+	if err != nil {
+		return err
 	}
 
 	//////////////////////////////////////////////////////////
-	// ASSIGN APPLICATION NAME
+	// ASSIGN APPLICATION NAME, DEBUG MODE AND SHUTDOWN TIMEOUT
 	//////////////////////////////////////////////////////////
-	c.AppName = os.Getenv("APP_NAME")
+	c.AppName = c.Config.AppName
+	c.Debug = c.Config.Debug
+	c.ShutdownTimeout = c.Config.ShutdownTimeout
+
+	//c.InfoLog.Printf("%s (c.Config.Port): %d\n", logSnippet, c.Config.Port)
+	//c.InfoLog.Printf("%s (c.Config.Renderer): %s\n", logSnippet, c.Config.Renderer)
 
 	//////////////////////////////////////////////////////////
-	// READ ENVIRONMENT VARIABLES AND ASSIGN VALUES TO
-	// CORRESPONDING MEMBERS OF Celeritas struct
+	// CONNECT TO DATABASE
 	//////////////////////////////////////////////////////////
-	c.Debug, err = strconv.ParseBool(os.Getenv("DEBUG"))
-	if err != nil {
-		c.ErrorLog.Println(err)
-		return err
+	if o.db != nil {
+		c.DB = Database{
+			DataType: c.Config.Database.Type,
+			Pool:     o.db,
+			Writer:   o.db,
+			Reader:   o.db,
+		}
+	} else if c.Config.Database.Type != "" && !listingRoutes() {
+		db, err := c.connectDB(c.BuildDSN())
+		if err != nil {
+			c.ErrorLog.Println(err)
+			return err
+		}
+		c.ownsDB = true
+		c.DB = Database{
+			DataType: c.Config.Database.Type,
+			Pool:     db,
+			Writer:   db,
+			Reader:   db,
+		}
+
+		if c.Config.Database.ReadHost != "" {
+			replica := c.Config.Database
+			replica.Host = replica.ReadHost
+			if replica.ReadPort != 0 {
+				replica.Port = replica.ReadPort
+			}
+
+			c.DB.Reader, err = c.connectDB(c.buildDSN(replica))
+			if err != nil {
+				c.ErrorLog.Println(err)
+				db.Close()
+				return err
+			}
+		}
 	}
 
 	//////////////////////////////////////////////////////////
-	// ASSIGN CONFIGURATION FOR CELERITAS
+	// CONNECT TO CACHE
 	//////////////////////////////////////////////////////////
-	c.config = config{
-		port:     os.Getenv("PORT"),
-		renderer: os.Getenv("RENDERER"),
-		cookie: cookieConfig{
-			name:     os.Getenv("COOKIE_NAME"),
-			lifetime: os.Getenv("COOKIE_LIFETIME"),
-			persist:  os.Getenv("COOKIE_PERSISTS"),
-			secure:   os.Getenv("COOKIE_SECURE"),
-			domain:   os.Getenv("COOKIE_DOMAIN"),
-		},
-		sessionType: os.Getenv("SESSION_TYPE"),
-		database: databaseConfig{
-			database: os.Getenv("DATABASE_TYPE"),
-			dsn:      c.BuildDSN(),
-		},
-		redis: redisConig{
-			host:     os.Getenv("REDIS_HOST"),
-			password: os.Getenv("REDIS_PASSWORD"),
-			prefix:   os.Getenv("REDIS_PREFIX"),
-		},
+	c.Scheduler = cron.New()
+	err = c.setupCache(o.cache)
+	if err != nil {
+		return err
 	}
 
-	//c.InfoLog.Printf("%s (c.config.port): %s\n", logSnippet, c.config.port)
-	//c.InfoLog.Printf("%s (c.config.renderer): %s\n", logSnippet, c.config.renderer)
-
 	//////////////////////////////////////////////////////////
 	// CREATE HTTP SESSION
 	//////////////////////////////////////////////////////////
-	httpSession := session.Session{
-		CookieLifetime: c.config.cookie.lifetime,
-		CookiePersist:  c.config.cookie.persist,
-		CookieName:     c.config.cookie.name,
-		SessionType:    c.config.sessionType,
-		CookieDomain:   c.config.cookie.domain,
-	}
-
-	switch c.config.sessionType {
-	case "redis":
-		httpSession.RedisPool = myRedisCache.Conn
-	case "mysql", "postgres", "mariadb", "postgresql":
-		httpSession.DBPool = c.DB.Pool
+	if o.session != nil {
+		c.Session = o.session
+	} else {
+		c.Session = c.createSession()
 	}
 
-	c.Session = httpSession.InitSession()
-
 	//////////////////////////////////////////////////////////
-	// READ ENCRYPTION KEY FROM .env FILE
+	// READ ENCRYPTION KEY FROM CONFIGURATION
 	//////////////////////////////////////////////////////////
-	c.EncryptionKey = os.Getenv("KEY")
+	c.EncryptionKey = c.Config.EncryptionKey
 	//c.EncryptionKey = "7zllP1TbvJv99l1xRJfHVtxff7ZfdX9d"
 	// fmt.Println("")
 	// fmt.Printf("c.EncryptionKey.......: '%s'", c.EncryptionKey)
 	// fmt.Printf("\nlen(c.EncryptionKey): '%d'", len(c.EncryptionKey))
 	// fmt.Println("")
 
+	//////////////////////////////////////////////////////////
+	// REGISTER METRICS
+	//////////////////////////////////////////////////////////
+	if c.Config.Metrics.Enabled {
+		err = c.setupMetrics()
+		if err != nil {
+			return err
+		}
+	}
+
 	/////////////////////////////////////////////////////
 	// ASSIGN AVAILABLE ROUTES
 	//////////////////////////////////////////////////////////
 	c.Routes = c.routes().(*chi.Mux)
 
 	//////////////////////////////////////////////////////////
-	// ASSIGN JET VIEWS
+	// ASSIGN PUBLIC FILES AND MIGRATIONS (EMBEDDED OR ON DISK)
 	//////////////////////////////////////////////////////////
-	var views = jet.NewSet(
-		jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
-		//jet.InDevelopmentMode(),
-	)
-	c.JetViews = views
+	c.Public = c.appFS(o.public, "public")
+	c.Migrations = c.appFS(o.migrations, "migrations")
 
-	logSnippet := "\n[celeritas][New] =>"
-	fmt.Println("")
-	fmt.Printf("%s (c.Debug): '%t'", logSnippet, c.Debug)
-	fmt.Println("")
+	//////////////////////////////////////////////////////////
+	// ASSIGN JET VIEWS
+	//////////////////////////////////////////////////////////
+	views := c.appFS(o.views, "views")
+	loader := render.NewFSLoader(views)
 
 	if c.Debug {
-		var views = jet.NewSet(
-			jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
-			jet.InDevelopmentMode(),
-		)
-		c.JetViews = views
+		c.JetViews = jet.NewSet(loader, jet.InDevelopmentMode())
 	} else {
-		var views = jet.NewSet(
-			jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
-		)
-		c.JetViews = views
+		c.JetViews = jet.NewSet(loader)
 	}
 
 	//////////////////////////////////////////////////////////
 	// ASSIGN TEMPLATE RENDERER
 	//////////////////////////////////////////////////////////
-	c.createRenderer()
+	return c.createRenderer(views)
+}
+
+// setupCache uses the given cache, or connects to the one configured.
+// A redis pool is also created when sessions are stored in redis.
+func (c *Celeritas) setupCache(given cache.Cache) error {
+	if given != nil {
+		c.Cache = given
+		switch x := given.(type) {
+		case *cache.RedisCache:
+			c.redisPool = x.Conn
+		case *cache.BadgerCache:
+			c.badgerConn = x.Conn
+		}
+	}
+
+	// listing the routes needs no connections (see RouteListEnv)
+	if listingRoutes() {
+		return nil
+	}
+
+	//////////////////////////////////////////////////////////
+	// CONNECT TO REDIS CACHE
+	//////////////////////////////////////////////////////////
+	if c.redisPool == nil && (c.Config.Cache == "redis" || c.Config.SessionType == "redis") {
+		redisCache := c.createClientRedisCache()
+		c.redisPool = redisCache.Conn
+		c.ownsRedis = true
+		if c.Cache == nil && c.Config.Cache == "redis" {
+			c.Cache = redisCache
+		}
+	}
+
+	//////////////////////////////////////////////////////////
+	// CONNECT TO BADGER CACHE
+	//////////////////////////////////////////////////////////
+	if c.Cache == nil && c.Config.Cache == "badger" {
+		badgerCache, err := c.createClientBadgerCache()
+		if err != nil {
+			return err
+		}
+		c.Cache = badgerCache
+		c.badgerConn = badgerCache.Conn
+		c.ownsBadger = true
+	}
+
+	if c.badgerConn != nil {
+		conn := c.badgerConn
+		_, err := c.Scheduler.AddFunc("@daily", func() {
+			_ = conn.RunValueLogGC(0.7)
+		})
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// createSession builds the session manager from the cookie and session
+// store configuration
+func (c *Celeritas) createSession() *scs.SessionManager {
+	httpSession := session.Session{
+		CookieLifetime: strconv.Itoa(c.Config.Cookie.Lifetime),
+		CookiePersist:  strconv.FormatBool(c.Config.Cookie.Persist),
+		CookieName:     c.Config.Cookie.Name,
+		SessionType:    c.Config.SessionType,
+		CookieDomain:   c.Config.Cookie.Domain,
+		CookieSecure:   strconv.FormatBool(c.Config.Cookie.Secure || c.Config.Secure),
+	}
+
+	// without connections (see RouteListEnv) only cookies can hold sessions
+	if listingRoutes() {
+		httpSession.SessionType = "cookie"
+	}
+
+	switch httpSession.SessionType {
+	case "redis":
+		httpSession.RedisPool = c.redisPool
+	case "mysql", "postgres", "mariadb", "postgresql", "sqlite", "sqlite3":
+		httpSession.DBPool = c.DB.Pool
+	}
+
+	return httpSession.InitSession()
+}
+
 func (c *Celeritas) Init(p initPaths) error {
 	// logSnippet := "\n[celeritas][Init] =>"
 	// fmt.Printf("%s (p.rootPath)..: %s\n", logSnippet, p.rootPath)
 
 	root := p.rootPath
 	for _, path := range p.folderNames {
-		err := c.CreateDirIfNotExist(root + "/" + path)
+		err := c.CreateDirIfNotExist(filepath.Join(root, path))
 		if err != nil {
 			return err
 		}
@@ -282,90 +391,177 @@ func (c *Celeritas) checkDotEnv(path string) error {
 	// logSnippet := "\n[celeritas][checkDotEnv] =>"
 	// fmt.Printf("%s (path)..: %s\n", logSnippet, path)
 
-	err := c.CreateFileIfNotExists(fmt.Sprintf("%s/.env", path))
+	err := c.CreateFileIfNotExists(filepath.Join(path, ".env"))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *Celeritas) startLoggers() (*log.Logger, *log.Logger) {
-	var infoLog *log.Logger
-	var errorLog *log.Logger
+// startLoggers creates the structured Logger, plus the InfoLog and
+// ErrorLog adapters which write through it at info and error level
+func (c *Celeritas) startLoggers() error {
+	level := c.Config.Log.Level
+	if level == "" {
+		level = "info"
+		if c.Config.Debug {
+			level = "debug"
+		}
+	}
+
+	opts := logging.Options{
+		Level:  level,
+		Format: c.Config.Log.Format,
+	}
+
+	if c.Config.Log.File != "" {
+		c.logFile = &logging.RotatingFile{
+			Filename:   filepath.Join(c.RootPath, "logs", c.Config.Log.File),
+			MaxSize:    int64(c.Config.Log.MaxSize) * 1024 * 1024,
+			MaxAge:     c.Config.Log.MaxAge,
+			MaxBackups: c.Config.Log.MaxBackups,
+		}
+		opts.File = c.logFile
+	}
+
+	logger, err := logging.New(opts)
+	if err != nil {
+		return err
+	}
 
-	infoLog = log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
-	errorLog = log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
+	c.Logger = logger
+	c.InfoLog = slog.NewLogLogger(logger.Handler(), slog.LevelInfo)
+	c.ErrorLog = slog.NewLogLogger(logger.Handler(), slog.LevelError)
 
-	return infoLog, errorLog
+	return nil
 }
 
-// ListentAndServe starts the web server
-func (c *Celeritas) ListenAndServe() {
+// ListenAndServe starts the web server and blocks until it fails or the
+// process receives SIGINT or SIGTERM. Either way the application is shut
+// down gracefully (see Shutdown) before ListenAndServe returns.
+//
+// When SECURE is true the server speaks HTTPS only, using the certificate
+// configured in c.Config.TLS, and optionally redirects plain HTTP from
+// TLS_REDIRECT_PORT.
+//
+// With CELERITAS_ROUTE_LIST set, it writes the route list there instead
+// (see RouteList), and shuts down.
+func (c *Celeritas) ListenAndServe() error {
+	if file := os.Getenv(RouteListEnv); file != "" {
+		err := c.writeRouteList(file)
+		return errors.Join(err, c.Shutdown(context.Background()))
+	}
+
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", os.Getenv("PORT")),
+		Addr:         fmt.Sprintf(":%d", c.Config.Port),
 		ErrorLog:     c.ErrorLog,
 		Handler:      c.Routes,
 		IdleTimeout:  30 * time.Second,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 600 * time.Second,
 	}
+	c.server = srv
 
-	/////////////////////////////////////////////
-	// CLOSE DATABASE WHEN APPLICTION SHUTS DOWN
-	/////////////////////////////////////////////
-	if c.DB.Pool != nil {
-		defer c.DB.Pool.Close()
+	if c.Config.Secure {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = tlsConfig
 	}
 
-	////////////////////////////////////////////////
-	// CLOSE REDIS CACHE WHEN APPLICTION SHUTS DOWN
-	////////////////////////////////////////////////
-	if redisPool != nil {
-		defer redisPool.Close()
-	}
+	serverErrors := make(chan error, 2)
+	go func() {
+		if c.Config.Secure {
+			c.Logger.Info("listening", "port", c.Config.Port, "tls", true)
+			serverErrors <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		c.Logger.Info("listening", "port", c.Config.Port, "tls", false)
+		serverErrors <- srv.ListenAndServe()
+	}()
 
-	////////////////////////////////////////////////
-	// CLOSE BADGER CACHE WHEN APPLICTION SHUTS DOWN
-	////////////////////////////////////////////////
-	if badgerConn != nil {
-		defer badgerConn.Close()
+	if c.Config.Secure && c.Config.TLS.RedirectPort > 0 {
+		c.startRedirectServer(serverErrors)
 	}
 
-	c.InfoLog.Printf("Listening on port %s", os.Getenv("PORT"))
-	err := srv.ListenAndServe()
-	c.ErrorLog.Fatal(err)
+	return c.waitForShutdown(serverErrors)
 }
 
-func (c *Celeritas) createRenderer() {
+func (c *Celeritas) createRenderer(views fs.FS) error {
 	myRenderer := render.Render{
-		Renderer: c.config.renderer,
-		RootPath: c.RootPath,
-		Port:     c.config.port,
-		JetViews: c.JetViews,
-		Session:  c.Session,
+		Renderer:   c.Config.Renderer,
+		RootPath:   c.RootPath,
+		Port:       strconv.Itoa(c.Config.Port),
+		ServerName: c.Config.ServerName,
+		Secure:     c.Config.Secure,
+		JetViews:   c.JetViews,
+		Views:      views,
+		Session:    c.Session,
+		UseCache:   !c.Debug,
+		Funcs: template.FuncMap{
+			"asset":       c.Asset,
+			"route":       c.templateURL,
+			"signedURL":   c.templateSignedURL,
+			"signedRoute": c.templateSignedRoute,
+		},
 	}
 	c.Render = &myRenderer
+
+	// load every template engine up front, so that a broken template is
+	// found now rather than by the first request for it
+	if err := myRenderer.Load(); err != nil {
+		return err
+	}
+
+	// jet ignores the error a function returns, so it gets versions which
+	// panic instead, and jet reports the panic as the template's error
+	jetEngine, err := myRenderer.Engine("jet")
+	if err != nil {
+		return err
+	}
+	jetEngine.AddFuncs(map[string]interface{}{
+		"route":       c.URL,
+		"signedURL":   c.jetSignedURL,
+		"signedRoute": c.jetSignedRoute,
+	})
+
+	return nil
 }
 
+// BuildDSN returns the data source name for the configured database
 func (c *Celeritas) BuildDSN() string {
+	return c.buildDSN(c.Config.Database)
+}
+
+func (c *Celeritas) buildDSN(db DatabaseConfig) string {
 	var dsn string
 
-	//databaseType := os.Getenv("DATABASE_TYPE")
-	//c.InfoLog.Printf("[celeritas][BuildDSN]: (databaseType): '%s';", databaseType)
+	//c.InfoLog.Printf("[celeritas][BuildDSN]: (db.Type): '%s';", db.Type)
 
-	switch os.Getenv("DATABASE_TYPE") {
+	switch db.Type {
 	case "postgres", "postgresql":
-		dsn = fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=%s timezone=UTC connect_timeout=5",
-			os.Getenv("DATABASE_HOST"),
-			os.Getenv("DATABASE_PORT"),
-			os.Getenv("DATABASE_USER"),
-			os.Getenv("DATABASE_NAME"),
-			os.Getenv("DATABASE_SSL_MODE"))
-
-		if os.Getenv("DATABASE_PASS") != "" {
-			dsn = fmt.Sprintf("%s password=%s", dsn, os.Getenv("DATABASE_PASS"))
+		dsn = fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=%s timezone=UTC connect_timeout=5",
+			db.Host,
+			db.Port,
+			db.User,
+			db.Name,
+			db.SSLMode)
+
+		if db.Password != "" {
+			dsn = fmt.Sprintf("%s password=%s", dsn, db.Password)
 		}
 
+		if db.SSLRootCert != "" {
+			dsn = fmt.Sprintf("%s sslrootcert=%s", dsn, c.databasePath(db.SSLRootCert))
+		}
+
+	case "mysql", "mariadb":
+		dsn = c.mysqlDSN(db)
+
+	case "sqlite", "sqlite3":
+		dsn = c.sqliteDSN(db)
+
 	default:
 	}
 
@@ -380,8 +576,8 @@ func (c *Celeritas) createRedisPool() *redis.Pool {
 		IdleTimeout: 240 * time.Second,
 		Dial: func() (redis.Conn, error) {
 			return redis.Dial("tcp",
-				c.config.redis.host,
-				redis.DialPassword(c.config.redis.password))
+				c.Config.Redis.Host,
+				redis.DialPassword(c.Config.Redis.Password))
 		},
 
 		TestOnBorrow: func(conn redis.Conn, t time.Time) error {
@@ -391,25 +587,25 @@ func (c *Celeritas) createRedisPool() *redis.Pool {
 	}
 }
 
-func (c *Celeritas) createBadgerConn() *badger.DB {
-	db, err := badger.Open(badger.DefaultOptions(c.RootPath + "/tmp/badger"))
-	if err != nil {
-		return nil
-	}
-	return db
+func (c *Celeritas) createBadgerConn() (*badger.DB, error) {
+	return badger.Open(badger.DefaultOptions(filepath.Join(c.RootPath, "tmp", "badger")))
 }
 
 func (c *Celeritas) createClientRedisCache() *cache.RedisCache {
 	cacheClient := cache.RedisCache{
 		Conn:   c.createRedisPool(),
-		Prefix: c.config.redis.prefix,
+		Prefix: c.Config.Redis.Prefix,
 	}
 	return &cacheClient
 }
 
-func (c *Celeritas) createClientBadgerCache() *cache.BadgerCache {
+func (c *Celeritas) createClientBadgerCache() (*cache.BadgerCache, error) {
+	conn, err := c.createBadgerConn()
+	if err != nil {
+		return nil, err
+	}
 	cacheClient := cache.BadgerCache{
-		Conn: c.createBadgerConn(),
+		Conn: conn,
 	}
-	return &cacheClient
+	return &cacheClient, nil
 }
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/fatih/color"
+)
+
+// doAssetsBuild fingerprints the files under public and writes the asset
+// manifest used by the asset() template function
+func doAssetsBuild() error {
+	manifest, err := cel.BuildAssets()
+	if err != nil {
+		return err
+	}
+
+	color.Yellow("Fingerprinted %d files into public/assets.json", len(manifest))
+	return nil
+}
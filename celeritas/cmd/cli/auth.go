@@ -12,22 +12,27 @@ func doAuth() error {
 	//////////////////////////////////////////////////////////////////////////////////////////////////
 	// CREATE MIGRATIONS
 	//////////////////////////////////////////////////////////////////////////////////////////////////
-	dbType := cel.DB.DataType
+	database := dbType()
 	fileName := fmt.Sprintf("%d_create_auth_tables", time.Now().UnixMicro())
 	upFile := cel.RootPath + "/migrations/" + fileName + ".up.sql"
 	downFile := cel.RootPath + "/migrations/" + fileName + ".down.sql"
 
-	log.Println("dbType..:", dbType)
+	log.Println("dbType..:", database)
 	log.Println("fileName:", fileName)
 	log.Println("upFile..:", upFile)
 	log.Println("downFile:", downFile)
 
-	err := copyFileFromTemplate("templates/migrations/auth_tables."+dbType+".sql", upFile)
+	err := copyFileFromTemplate("templates/migrations/auth_tables."+database+".sql", upFile)
 	if err != nil {
 		exitGracefully(err)
 	}
 
-	err = copyDataToFile([]byte("drop table if exists users cascade; drop table if exists tokens cascade; drop table if exists remember_tokens;"), downFile)
+	down := "drop table if exists users cascade; drop table if exists tokens cascade; drop table if exists remember_tokens;"
+	if database == "sqlite" {
+		down = "drop table if exists tokens; drop table if exists remember_tokens; drop table if exists users;"
+	}
+
+	err = copyDataToFile([]byte(down), downFile)
 	if err != nil {
 		exitGracefully(err)
 	}
@@ -3,56 +3,76 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/fatih/color"
-	"github.com/joho/godotenv"
+	"github.com/leetrent/celeritas"
 )
 
 func setup() {
-	err := godotenv.Load()
+	path, err := os.Getwd()
 	if err != nil {
 		exitGracefully(err)
 	}
-	path, err := os.Getwd()
+
+	cfg, err := celeritas.LoadConfig(path)
 	if err != nil {
 		exitGracefully(err)
 	}
+
 	cel.RootPath = path
-	cel.DB.DataType = os.Getenv("DATABASE_TYPE")
+	cel.Config = *cfg
+	cel.DB.DataType = cfg.Database.Type
 }
 
 func getDSN() string {
-	dbType := cel.DB.DataType
-
-	if dbType == "pgx" {
-		dbType = "postgres"
-	}
+	db := cel.Config.Database
 
 	var dsn string
-	if dbType == "postgres" {
-		if os.Getenv("DATABASE_PASS") != "" {
-			dsn = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-				os.Getenv("DATABASE_USER"),
-				os.Getenv("DATABASE_PASS"),
-				os.Getenv("DATABASE_HOST"),
-				os.Getenv("DATABASE_PORT"),
-				os.Getenv("DATABASE_NAME"),
-				os.Getenv("DATABASE_SSL_MODE"))
+	switch dbType() {
+	case "postgres":
+		if db.Password != "" {
+			dsn = fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+				db.User,
+				db.Password,
+				db.Host,
+				db.Port,
+				db.Name,
+				db.SSLMode)
 		} else {
-			dsn = fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=%s",
-				os.Getenv("DATABASE_USER"),
-				os.Getenv("DATABASE_HOST"),
-				os.Getenv("DATABASE_PORT"),
-				os.Getenv("DATABASE_NAME"),
-				os.Getenv("DATABASE_SSL_MODE"))
+			dsn = fmt.Sprintf("postgres://%s@%s:%d/%s?sslmode=%s",
+				db.User,
+				db.Host,
+				db.Port,
+				db.Name,
+				db.SSLMode)
+		}
+	case "sqlite":
+		dsn = "sqlite://" + filepath.ToSlash(filepath.Join(cel.RootPath, db.Name))
+		if filepath.IsAbs(db.Name) {
+			dsn = "sqlite://" + filepath.ToSlash(db.Name)
 		}
-	} else {
+	default:
 		dsn = "mysql://" + cel.BuildDSN()
 	}
 
 	return dsn
 }
 
+// dbType returns the configured database type as used in migration and
+// template file names: postgres, mysql or sqlite
+func dbType() string {
+	switch cel.DB.DataType {
+	case "pgx", "postgresql":
+		return "postgres"
+	case "mariadb":
+		return "mysql"
+	case "sqlite3":
+		return "sqlite"
+	}
+	return cel.DB.DataType
+}
+
 func showHelp() {
 	color.Yellow(`Available commands:
 	help                  - show the help commands
@@ -60,9 +80,14 @@ func showHelp() {
 	migrate               - runs all 'up' migrations that have not been run previously
 	migrate down          - reverses the most recent 'up' migration
 	migrate reset         - runs all 'down' migrations in reverse order, and then all 'up' migrations
+	down                  - puts the application into maintenance mode (flags: --secret <secret> --retry <seconds>)
+	up                    - takes the application out of maintenance mode
+	routes                - lists the application's routes (flags: --method <method> --path <text> --json)
+	assets:build          - fingerprints the files in public and writes public/assets.json
 	make migration <name> - creates two (2) new 'up' and 'down' migrations in the migrations folder
 	make auth             - create and runs migrations for authentication tables, and creates models and middleware
 	make handler <name>   - creates a stub handler in the handlers directory
+	make resource <name>  - creates a resource handler with index, create, store, show, edit, update and destroy actions
 	make model <name>     - creates a new model in the data directory
 	make session          - creates a table in the database as a session store
 	`)
@@ -14,18 +14,25 @@ var cel celeritas.Celeritas
 
 func main() {
 	var message string
+	var quiet bool
 	arg1, arg2, arg3, err := validateInput()
 	if err != nil {
 		exitGracefully(err)
 	}
 
-	setup()
-
+	// help and version work outside a configured application
 	switch arg1 {
 	case "help":
 		showHelp()
+		exitGracefully(nil)
 	case "version":
 		showVersion()
+		exitGracefully(nil)
+	}
+
+	setup()
+
+	switch arg1 {
 	case "migrate":
 		if arg2 == "" {
 			arg2 = "up"
@@ -35,9 +42,29 @@ func main() {
 			exitGracefully(err)
 		}
 		message = "Migrations complete!"
+	case "down":
+		err = doDown(os.Args[2:])
+		if err != nil {
+			exitGracefully(err)
+		}
+	case "up":
+		err = doUp()
+		if err != nil {
+			exitGracefully(err)
+		}
+	case "routes":
+		quiet, err = doRoutes(os.Args[2:])
+		if err != nil {
+			exitGracefully(err)
+		}
+	case "assets:build":
+		err = doAssetsBuild()
+		if err != nil {
+			exitGracefully(err)
+		}
 	case "make":
 		if arg2 == "" {
-			exitGracefully(errors.New("make requires a subcommand: [migration|model|handler]"))
+			exitGracefully(errors.New("make requires a subcommand: [migration|model|handler|resource]"))
 		}
 		err = doMake(arg2, arg3)
 		if err != nil {
@@ -47,6 +74,10 @@ func main() {
 		showHelp()
 	}
 
+	// machine-readable output is left without a closing message
+	if quiet {
+		return
+	}
 	exitGracefully(nil, message)
 }
 
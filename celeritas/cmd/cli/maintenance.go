@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/fatih/color"
+	"github.com/leetrent/celeritas"
+)
+
+// doDown puts the application into maintenance mode, e.g.
+// celeritas down --secret let-me-in --retry 60
+func doDown(args []string) error {
+	flags := flag.NewFlagSet("down", flag.ContinueOnError)
+	secret := flags.String("secret", "", "visiting /<secret> sets a cookie which bypasses maintenance mode")
+	retry := flags.Int("retry", 0, "seconds to send in the Retry-After header")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	err = cel.Down(celeritas.MaintenanceMode{
+		Secret: *secret,
+		Retry:  *retry,
+	})
+	if err != nil {
+		return err
+	}
+
+	color.Yellow("Application is now in maintenance mode.")
+	if *secret != "" {
+		color.Yellow("Bypass it by visiting /%s", *secret)
+	}
+
+	return nil
+}
+
+// doUp takes the application out of maintenance mode
+func doUp() error {
+	err := cel.Up()
+	if err != nil {
+		return err
+	}
+
+	color.Yellow("Application is now live.")
+	return nil
+}
@@ -19,20 +19,20 @@ func doMake(arg2, arg3 string) error {
 		color.Yellow("32 character encryption key: '%s'", rnd)
 		fmt.Println("len(rnd):", len(rnd))
 	case "migration":
-		dbType := cel.DB.DataType
+		database := dbType()
 		if arg3 == "" {
 			exitGracefully(errors.New("you must give the migration a name"))
 		}
 		fileName := fmt.Sprintf("%d_%s", time.Now().UnixMicro(), arg3)
-		upFile := cel.RootPath + "/migrations/" + fileName + "." + dbType + ".up.sql"
-		downFile := cel.RootPath + "/migrations/" + fileName + "." + dbType + ".down.sql"
+		upFile := cel.RootPath + "/migrations/" + fileName + "." + database + ".up.sql"
+		downFile := cel.RootPath + "/migrations/" + fileName + "." + database + ".down.sql"
 
-		err := copyFileFromTemplate("templates/migrations/migration."+dbType+".up.sql", upFile)
+		err := copyFileFromTemplate("templates/migrations/migration."+database+".up.sql", upFile)
 		if err != nil {
 			exitGracefully(err)
 		}
 
-		err = copyFileFromTemplate("templates/migrations/migration."+dbType+".down.sql", downFile)
+		err = copyFileFromTemplate("templates/migrations/migration."+database+".down.sql", downFile)
 		if err != nil {
 			exitGracefully(err)
 		}
@@ -65,6 +65,35 @@ func doMake(arg2, arg3 string) error {
 			exitGracefully(err)
 		}
 
+	case "resource":
+		if arg3 == "" {
+			exitGracefully(errors.New("you must give the resource a name"))
+		}
+
+		plur := pluralize.NewClient()
+		routeName := strcase.ToKebab(plur.Plural(arg3))
+
+		fileName := cel.RootPath + "/handlers/" + strcase.ToSnake(plur.Plural(arg3)) + ".go"
+		if fileExists(fileName) {
+			exitGracefully(errors.New(fileName + " already exists"))
+		}
+
+		data, err := templateFS.ReadFile("templates/handlers/resource.go.txt")
+		if err != nil {
+			exitGracefully(err)
+		}
+
+		resource := string(data)
+		resource = strings.ReplaceAll(resource, "$RESOURCENAME$", strcase.ToCamel(plur.Plural(arg3)))
+		resource = strings.ReplaceAll(resource, "$ROUTENAME$", routeName)
+
+		err = copyDataToFile([]byte(resource), fileName)
+		if err != nil {
+			exitGracefully(err)
+		}
+
+		color.Yellow("Register it in routes.go with a.App.Resource(\"/%s\", &handlers.%sResource{Handlers: a.Handlers})", routeName, strcase.ToCamel(plur.Plural(arg3)))
+
 	case "model":
 		if arg3 == "" {
 			exitGracefully(errors.New("you must give the model a name"))
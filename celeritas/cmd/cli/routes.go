@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leetrent/celeritas"
+)
+
+// doRoutes lists the application's routes, e.g.
+// celeritas routes --method GET --path /users --json
+//
+// The application is built and started with CELERITAS_ROUTE_LIST set,
+// which makes it write its routes to a file and exit instead of serving.
+// It reports whether the output was JSON, which the closing message must
+// not follow.
+func doRoutes(args []string) (bool, error) {
+	flags := flag.NewFlagSet("routes", flag.ContinueOnError)
+	method := flags.String("method", "", "only list routes for this method")
+	path := flags.String("path", "", "only list routes whose pattern contains this")
+	asJSON := flags.Bool("json", false, "print the routes as JSON")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return false, err
+	}
+
+	tmp, err := os.MkdirTemp("", "celeritas-routes")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmp)
+	file := filepath.Join(tmp, "routes.json")
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = cel.RootPath
+	cmd.Env = append(os.Environ(), celeritas.RouteListEnv+"="+file)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return false, fmt.Errorf("running the application: %w", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	var routes []celeritas.RouteInfo
+	err = json.Unmarshal(data, &routes)
+	if err != nil {
+		return false, err
+	}
+
+	routes = filterRoutes(routes, *method, *path)
+
+	if *asJSON {
+		out, err := json.MarshalIndent(routes, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(string(out))
+		return true, nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATTERN\tNAME\tHANDLER\tMIDDLEWARE")
+	for _, route := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Pattern, route.Name, route.Handler, strings.Join(route.Middleware, " > "))
+	}
+	return false, w.Flush()
+}
+
+// filterRoutes keeps the routes for method (ANY routes match every
+// method) whose pattern contains path
+func filterRoutes(routes []celeritas.RouteInfo, method, path string) []celeritas.RouteInfo {
+	var filtered []celeritas.RouteInfo
+	for _, route := range routes {
+		if method != "" && !strings.EqualFold(route.Method, method) && route.Method != "ANY" {
+			continue
+		}
+		if path != "" && !strings.Contains(route.Pattern, path) {
+			continue
+		}
+		filtered = append(filtered, route)
+	}
+	return filtered
+}
@@ -6,19 +6,13 @@ import (
 )
 
 func doSessionTable() error {
-	dbType := cel.DB.DataType
-	if dbType == "mariadb" {
-		dbType = "mysql"
-	}
-	if dbType == "postgresql" {
-		dbType = "postgres"
-	}
+	database := dbType()
 
 	fileName := fmt.Sprintf("%d_create_sessions_table", time.Now().UnixMicro())
-	upFile := cel.RootPath + "/migrations/" + fileName + "." + dbType + ".up.sql"
-	downFile := cel.RootPath + "/migrations/" + fileName + "." + dbType + ".down.sql"
+	upFile := cel.RootPath + "/migrations/" + fileName + "." + database + ".up.sql"
+	downFile := cel.RootPath + "/migrations/" + fileName + "." + database + ".down.sql"
 
-	err := copyFileFromTemplate("templates/migrations/"+dbType+"_session.sql", upFile)
+	err := copyFileFromTemplate("templates/migrations/"+database+"_session.sql", upFile)
 	if err != nil {
 		exitGracefully(err)
 	}
@@ -33,7 +33,7 @@ drop table if exists tokens cascade;
 CREATE TABLE `tokens` (
     `id` int(11) NOT NULL AUTO_INCREMENT,
     `user_id` int(11) unsigned NOT NULL,
-    `name` varchar(255) NOT NULL,
+    `first_name` varchar(255) NOT NULL,
     `email` varchar(255) NOT NULL,
     `token` varchar(255) NOT NULL,
     `token_hash` varbinary(255) DEFAULT NULL,
@@ -0,0 +1,7 @@
+-- CREATE TABLE some_table (
+--     id int(10) unsigned NOT NULL AUTO_INCREMENT,
+--     some_field varchar(255) NOT NULL,
+--     created_at timestamp NOT NULL DEFAULT current_timestamp(),
+--     updated_at timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+--     PRIMARY KEY (`id`)
+-- ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
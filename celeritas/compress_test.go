@@ -0,0 +1,131 @@
+package celeritas
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	var tests = []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"*", "br"},
+		{"identity", ""},
+	}
+
+	for _, e := range tests {
+		if got := negotiateEncoding(e.header); got != e.expected {
+			t.Errorf("%q: expected %q, got %q", e.header, e.expected, got)
+		}
+	}
+}
+
+func TestCeleritas_Compress(t *testing.T) {
+	c := &Celeritas{Config: Config{Compression: CompressionConfig{Enabled: true, Level: 5, MinSize: 100}}}
+	large := strings.Repeat("celeritas ", 100)
+
+	var tests = []struct {
+		name        string
+		encoding    string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{"gzip", "gzip", "text/html", large, "gzip"},
+		{"brotli", "gzip, br", "application/json", large, "br"},
+		{"too small", "gzip", "text/html", "small", ""},
+		{"image", "gzip", "image/png", large, ""},
+		{"svg", "gzip", "image/svg+xml", large, "gzip"},
+		{"not accepted", "", "text/html", large, ""},
+	}
+
+	for _, e := range tests {
+		handler := c.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", e.contentType)
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = io.WriteString(w, e.body)
+		}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept-Encoding", e.encoding)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Encoding"); got != e.expected {
+			t.Errorf("%s: expected Content-Encoding %q, got %q", e.name, e.expected, got)
+			continue
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: expected Vary: Accept-Encoding", e.name)
+		}
+		etag := `"v1"`
+		if e.expected != "" {
+			etag = `W/"v1"`
+		}
+		if got := w.Header().Get("ETag"); got != etag {
+			t.Errorf("%s: expected ETag %s, got %s", e.name, etag, got)
+		}
+
+		var body io.Reader = w.Body
+		switch e.expected {
+		case "gzip":
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("%s: %s", e.name, err)
+			}
+			body = zr
+		case "br":
+			body = brotli.NewReader(w.Body)
+		}
+		out, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("%s: %s", e.name, err)
+		}
+		if string(out) != e.body {
+			t.Errorf("%s: body did not round trip", e.name)
+		}
+	}
+}
+
+func TestCeleritas_CompressFlush(t *testing.T) {
+	c := &Celeritas{Config: Config{Compression: CompressionConfig{Enabled: true, Level: 5, MinSize: 1024}}}
+
+	handler := c.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+
+		// the first event must already be readable by the client
+		zr, err := gzip.NewReader(strings.NewReader(w.(*compressWriter).ResponseWriter.(*httptest.ResponseRecorder).Body.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 13)
+		if _, err := io.ReadFull(zr, buf); err != nil || string(buf) != "data: first\n\n" {
+			t.Errorf("flushed event not readable: %q %v", buf, err)
+		}
+
+		_, _ = io.WriteString(w, "data: second\n\n")
+	}))
+
+	r := httptest.NewRequest("GET", "/events", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if !w.Flushed || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a flushed gzip stream, got %v", w.Header())
+	}
+}
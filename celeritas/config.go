@@ -0,0 +1,577 @@
+package celeritas
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/leetrent/celeritas/logging"
+)
+
+// Config is the typed configuration of a celeritas application. Fields are
+// populated from the environment using struct tags:
+//
+//	env:"NAME"        the environment variable to read
+//	default:"value"   used when the variable is unset or empty
+//	required:"true"   boot fails when neither a value nor a default exists
+//
+// Supported field types are string, bool, int, int64, float64, []string
+// (comma separated) and time.Duration (a Go duration such as "90s", or a
+// bare integer meaning seconds). Nested structs without an env tag are
+// loaded recursively.
+type Config struct {
+	AppName         string        `env:"APP_NAME"`
+	AppEnv          string        `env:"APP_ENV"`
+	Debug           bool          `env:"DEBUG" default:"false"`
+	Port            int           `env:"PORT" default:"4000"`
+	ServerName      string        `env:"SERVER_NAME" default:"localhost"`
+	Secure          bool          `env:"SECURE" default:"false"`
+	Renderer        string        `env:"RENDERER" default:"jet"`
+	SessionType     string        `env:"SESSION_TYPE" default:"cookie"`
+	Cache           string        `env:"CACHE"`
+	EncryptionKey   string        `env:"KEY"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	Cookie          CookieConfig
+	Database        DatabaseConfig
+	Redis           RedisConfig
+	TLS             TLSConfig
+	Log             LogConfig
+	Health          HealthConfig
+	Metrics         MetricsConfig
+	Maintenance     MaintenanceConfig
+	CORS            CORSConfig
+	Security        SecurityConfig
+	Compression     CompressionConfig
+
+	// Sections holds application specific configuration registered
+	// with Extend, keyed by section name.
+	Sections map[string]interface{}
+}
+
+// CookieConfig holds the session cookie settings
+type CookieConfig struct {
+	Name     string `env:"COOKIE_NAME" default:"celeritas"`
+	Lifetime int    `env:"COOKIE_LIFETIME" default:"1440"`
+	Persist  bool   `env:"COOKIE_PERSIST" default:"true"`
+	Secure   bool   `env:"COOKIE_SECURE" default:"false"`
+	Domain   string `env:"COOKIE_DOMAIN"`
+}
+
+// DatabaseConfig holds the database connection settings. For sqlite, Name
+// is the path of the database file, relative to the application root, or
+// :memory: for an in-memory database.
+type DatabaseConfig struct {
+	Type     string `env:"DATABASE_TYPE"`
+	Host     string `env:"DATABASE_HOST"`
+	Port     int    `env:"DATABASE_PORT"`
+	User     string `env:"DATABASE_USER"`
+	Password string `env:"DATABASE_PASS"`
+	Name     string `env:"DATABASE_NAME"`
+
+	// SSLMode uses the postgres names (disable, require, verify-ca,
+	// verify-full) for every database; mysql also accepts preferred.
+	SSLMode     string `env:"DATABASE_SSL_MODE" default:"disable"`
+	SSLRootCert string `env:"DATABASE_SSL_ROOT_CERT"`
+
+	// Collation is the mysql/mariadb connection collation
+	Collation string `env:"DATABASE_COLLATION" default:"utf8mb4_unicode_ci"`
+
+	// connection pool settings, applied to the writer and reader pools
+	MaxOpenConns    int           `env:"DATABASE_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `env:"DATABASE_MAX_IDLE_CONNS" default:"25"`
+	ConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" default:"5m"`
+
+	// ConnectTimeout is how long to keep retrying, with backoff, when the
+	// database is not reachable at startup. Zero tries only once.
+	ConnectTimeout time.Duration `env:"DATABASE_CONNECT_TIMEOUT" default:"30s"`
+
+	// ReadHost, when set, is a read replica which shares the primary's
+	// credentials and database name. ReadPort defaults to Port.
+	ReadHost string `env:"DATABASE_READ_HOST"`
+	ReadPort int    `env:"DATABASE_READ_PORT"`
+}
+
+// RedisConfig holds the redis connection settings
+type RedisConfig struct {
+	Host     string `env:"REDIS_HOST"`
+	Password string `env:"REDIS_PASSWORD"`
+	Prefix   string `env:"REDIS_PREFIX"`
+}
+
+// TLSConfig holds the HTTPS settings used when SECURE is true
+type TLSConfig struct {
+	CertFile     string   `env:"TLS_CERT_FILE"`
+	KeyFile      string   `env:"TLS_KEY_FILE"`
+	MinVersion   string   `env:"TLS_MIN_VERSION" default:"1.2"`
+	CipherSuites []string `env:"TLS_CIPHER_SUITES"`
+
+	// SelfSigned generates (and caches under tmp/tls) a self-signed
+	// certificate for development instead of using CertFile and KeyFile.
+	SelfSigned bool `env:"TLS_SELF_SIGNED" default:"false"`
+
+	// RedirectPort, when set, starts a plain HTTP listener on that port
+	// which redirects every request to HTTPS.
+	RedirectPort int `env:"TLS_REDIRECT_PORT"`
+
+	HSTSMaxAge            int  `env:"HSTS_MAX_AGE" default:"31536000"`
+	HSTSIncludeSubdomains bool `env:"HSTS_INCLUDE_SUBDOMAINS" default:"false"`
+	HSTSPreload           bool `env:"HSTS_PRELOAD" default:"false"`
+}
+
+// LogConfig holds the logging settings. Level defaults to debug when
+// DEBUG is true and to info otherwise. When File is set, log lines are
+// also written to that file inside the logs folder, which is rotated once
+// it reaches MaxSize megabytes or MaxAge, keeping MaxBackups old files.
+type LogConfig struct {
+	Level      string        `env:"LOG_LEVEL"`
+	Format     string        `env:"LOG_FORMAT" default:"text"`
+	File       string        `env:"LOG_FILE"`
+	MaxSize    int           `env:"LOG_MAX_SIZE" default:"100"`
+	MaxAge     time.Duration `env:"LOG_MAX_AGE" default:"24h"`
+	MaxBackups int           `env:"LOG_MAX_BACKUPS" default:"7"`
+}
+
+// HealthConfig holds the settings for the liveness and readiness
+// endpoints, which are served at Path/live and Path/ready
+type HealthConfig struct {
+	Enabled bool          `env:"HEALTH_ENABLED" default:"true"`
+	Path    string        `env:"HEALTH_PATH" default:"/health"`
+	Timeout time.Duration `env:"HEALTH_TIMEOUT" default:"2s"`
+}
+
+// MetricsConfig holds the settings for the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool   `env:"METRICS_ENABLED" default:"false"`
+	Path    string `env:"METRICS_PATH" default:"/metrics"`
+}
+
+// MaintenanceConfig holds the maintenance mode settings. AllowedIPs are
+// addresses or CIDR ranges which can still use the application while it
+// is down. Client addresses honour X-Forwarded-For and X-Real-IP, so
+// these must be set by a proxy you trust.
+type MaintenanceConfig struct {
+	AllowedIPs []string `env:"MAINTENANCE_ALLOWED_IPS"`
+}
+
+// CORSConfig holds the cross-origin resource sharing settings for the
+// paths in Paths (a trailing /* matches everything below). CORS is off
+// while AllowedOrigins is empty. Origins may contain a wildcard, e.g.
+// https://*.example.com, or be just * to allow any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string `env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE"`
+	AllowedHeaders   []string `env:"CORS_ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type,X-CSRF-Token"`
+	ExposedHeaders   []string `env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `env:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	MaxAge           int      `env:"CORS_MAX_AGE" default:"300"`
+	Paths            []string `env:"CORS_PATHS" default:"/api/*"`
+}
+
+// SecurityConfig holds the security headers settings. CSP may contain
+// {nonce}, which is replaced by a fresh nonce for every request. With
+// CSPReportOnly, the default, the policy is reported on by browsers but
+// not enforced, so that existing inline styles and CDN assets keep
+// working until the application sets CSP_REPORT_ONLY=false.
+type SecurityConfig struct {
+	Headers           bool   `env:"SECURITY_HEADERS" default:"true"`
+	FrameOptions      string `env:"FRAME_OPTIONS" default:"SAMEORIGIN"`
+	ReferrerPolicy    string `env:"REFERRER_POLICY" default:"strict-origin-when-cross-origin"`
+	PermissionsPolicy string `env:"PERMISSIONS_POLICY" default:"camera=(), microphone=(), geolocation=()"`
+	CSP               string `env:"CSP" default:"default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; img-src 'self' data:; object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'self'"`
+	CSPReportOnly     bool   `env:"CSP_REPORT_ONLY" default:"true"`
+}
+
+// CompressionConfig holds the response compression settings. Level is
+// used for both gzip (1-9) and brotli; responses smaller than MinSize
+// bytes are not compressed.
+type CompressionConfig struct {
+	Enabled bool `env:"COMPRESSION_ENABLED" default:"true"`
+	Level   int  `env:"COMPRESSION_LEVEL" default:"5"`
+	MinSize int  `env:"COMPRESSION_MIN_SIZE" default:"1024"`
+}
+
+// ConfigError lists every configuration key that is missing or invalid,
+// so that all problems can be fixed in one go.
+type ConfigError struct {
+	Missing []string
+	Invalid []string
+}
+
+func (e *ConfigError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid "+strings.Join(e.Invalid, "; "))
+	}
+	return "configuration error: " + strings.Join(parts, "; ")
+}
+
+func (e *ConfigError) empty() bool {
+	return len(e.Missing) == 0 && len(e.Invalid) == 0
+}
+
+// LoadConfig reads rootPath/.env and, when APP_ENV is set, the
+// rootPath/.env.<APP_ENV> overlay into the environment, and then builds
+// and validates a Config from it. Variables already set in the real
+// environment take precedence over both files. Missing files are ignored.
+func LoadConfig(rootPath string) (*Config, error) {
+	err := loadEnvFiles(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ConfigFromEnv()
+}
+
+// ConfigFromEnv builds and validates a Config from the process
+// environment alone, without reading any .env files.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		Sections: make(map[string]interface{}),
+	}
+
+	cfgErr := &ConfigError{}
+	loadSection(reflect.ValueOf(cfg).Elem(), cfgErr)
+	cfg.validate(cfgErr)
+	if !cfgErr.empty() {
+		return nil, cfgErr
+	}
+
+	return cfg, nil
+}
+
+// Extend loads an application specific configuration section from the
+// environment into section, which must be a pointer to a struct using the
+// same tags as Config, and stores it under name in cfg.Sections.
+func (cfg *Config) Extend(name string, section interface{}) error {
+	v := reflect.ValueOf(section)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config section %s must be a pointer to a struct", name)
+	}
+
+	cfgErr := &ConfigError{}
+	loadSection(v.Elem(), cfgErr)
+	if !cfgErr.empty() {
+		return cfgErr
+	}
+
+	if cfg.Sections == nil {
+		cfg.Sections = make(map[string]interface{})
+	}
+	cfg.Sections[name] = section
+
+	return nil
+}
+
+// Section returns the application specific section registered under name
+func (cfg *Config) Section(name string) (interface{}, bool) {
+	section, ok := cfg.Sections[name]
+	return section, ok
+}
+
+// validate checks the rules which cannot be expressed with struct tags
+func (cfg *Config) validate(cfgErr *ConfigError) {
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("PORT=%d: must be between 1 and 65535", cfg.Port))
+	}
+
+	switch cfg.Database.Type {
+	case "":
+	case "postgres", "postgresql", "mysql", "mariadb":
+		if cfg.Database.Host == "" {
+			cfgErr.Missing = append(cfgErr.Missing, "DATABASE_HOST")
+		}
+		if cfg.Database.User == "" {
+			cfgErr.Missing = append(cfgErr.Missing, "DATABASE_USER")
+		}
+		if cfg.Database.Name == "" {
+			cfgErr.Missing = append(cfgErr.Missing, "DATABASE_NAME")
+		}
+	case "sqlite", "sqlite3":
+		if cfg.Database.Name == "" {
+			cfgErr.Missing = append(cfgErr.Missing, "DATABASE_NAME")
+		}
+		if cfg.Database.ReadHost != "" {
+			cfgErr.Invalid = append(cfgErr.Invalid, "DATABASE_READ_HOST: sqlite does not support read replicas")
+		}
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("DATABASE_TYPE=%q: unsupported database", cfg.Database.Type))
+	}
+
+	if cfg.Database.MaxIdleConns > cfg.Database.MaxOpenConns && cfg.Database.MaxOpenConns > 0 {
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("DATABASE_MAX_IDLE_CONNS=%d: must not exceed DATABASE_MAX_OPEN_CONNS", cfg.Database.MaxIdleConns))
+	}
+
+	switch cfg.Database.SSLMode {
+	case "disable", "allow", "prefer", "preferred", "require", "verify-ca", "verify-full":
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("DATABASE_SSL_MODE=%q: unknown mode", cfg.Database.SSLMode))
+	}
+
+	switch cfg.SessionType {
+	case "cookie", "redis":
+	case "mysql", "mariadb", "postgres", "postgresql", "sqlite", "sqlite3":
+		if cfg.Database.Type == "" {
+			cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("SESSION_TYPE=%q: requires DATABASE_TYPE", cfg.SessionType))
+		}
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("SESSION_TYPE=%q: unsupported session store", cfg.SessionType))
+	}
+
+	if cfg.Health.Enabled && !strings.HasPrefix(cfg.Health.Path, "/") {
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("HEALTH_PATH=%q: must start with /", cfg.Health.Path))
+	}
+
+	if cfg.Metrics.Enabled && !strings.HasPrefix(cfg.Metrics.Path, "/") {
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("METRICS_PATH=%q: must start with /", cfg.Metrics.Path))
+	}
+
+	for _, entry := range cfg.Maintenance.AllowedIPs {
+		if _, ok := parseIPNet(entry); !ok {
+			cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("MAINTENANCE_ALLOWED_IPS=%q: not an IP address or CIDR range", entry))
+		}
+	}
+
+	switch strings.ToUpper(cfg.Security.FrameOptions) {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("FRAME_OPTIONS=%q: must be DENY or SAMEORIGIN", cfg.Security.FrameOptions))
+	}
+
+	if cfg.Compression.Enabled && (cfg.Compression.Level < 1 || cfg.Compression.Level > 9) {
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("COMPRESSION_LEVEL=%d: must be between 1 and 9", cfg.Compression.Level))
+	}
+
+	switch cfg.Cache {
+	case "", "redis", "badger":
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("CACHE=%q: unsupported cache", cfg.Cache))
+	}
+
+	if (cfg.Cache == "redis" || cfg.SessionType == "redis") && cfg.Redis.Host == "" {
+		cfgErr.Missing = append(cfgErr.Missing, "REDIS_HOST")
+	}
+
+	if cfg.Secure {
+		if !cfg.TLS.SelfSigned && cfg.TLS.CertFile == "" {
+			cfgErr.Missing = append(cfgErr.Missing, "TLS_CERT_FILE")
+		}
+		if !cfg.TLS.SelfSigned && cfg.TLS.KeyFile == "" {
+			cfgErr.Missing = append(cfgErr.Missing, "TLS_KEY_FILE")
+		}
+		if _, ok := tlsVersions[cfg.TLS.MinVersion]; !ok {
+			cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("TLS_MIN_VERSION=%q: must be 1.0, 1.1, 1.2 or 1.3", cfg.TLS.MinVersion))
+		}
+		for _, name := range cfg.TLS.CipherSuites {
+			if _, ok := cipherSuiteID(name); !ok {
+				cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("TLS_CIPHER_SUITES: unknown or insecure cipher suite %s", name))
+			}
+		}
+		if cfg.TLS.RedirectPort == cfg.Port {
+			cfgErr.Invalid = append(cfgErr.Invalid, "TLS_REDIRECT_PORT: must differ from PORT")
+		}
+	}
+
+	if cfg.Log.Level != "" {
+		if _, err := logging.ParseLevel(cfg.Log.Level); err != nil {
+			cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("LOG_LEVEL=%q: must be debug, info, warn or error", cfg.Log.Level))
+		}
+	}
+
+	switch cfg.Log.Format {
+	case "text", "json":
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("LOG_FORMAT=%q: must be text or json", cfg.Log.Format))
+	}
+
+	switch len(cfg.EncryptionKey) {
+	case 0, 16, 24, 32:
+	default:
+		cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("KEY: must be 16, 24 or 32 characters long, not %d", len(cfg.EncryptionKey)))
+	}
+}
+
+// loadEnvFiles copies the entries of .env, overlaid with .env.<APP_ENV>,
+// into the process environment without overwriting existing variables
+func loadEnvFiles(rootPath string) error {
+	values, err := readEnvFile(filepath.Join(rootPath, ".env"))
+	if err != nil {
+		return err
+	}
+
+	appEnv := os.Getenv("APP_ENV")
+	if appEnv == "" {
+		appEnv = values["APP_ENV"]
+	}
+
+	if appEnv != "" {
+		overlay, err := readEnvFile(filepath.Join(rootPath, ".env."+appEnv))
+		if err != nil {
+			return err
+		}
+		for key, value := range overlay {
+			values[key] = value
+		}
+	}
+
+	for key, value := range values {
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		err = os.Setenv(key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	return godotenv.Read(path)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// loadSection populates the tagged fields of the struct v from the
+// environment, recording problems in cfgErr
+func loadSection(v reflect.Value, cfgErr *ConfigError) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		key := field.Tag.Get("env")
+		if key == "" {
+			if field.Type.Kind() == reflect.Struct {
+				loadSection(fieldValue, cfgErr)
+			}
+			continue
+		}
+
+		raw := os.Getenv(key)
+		if raw == "" {
+			raw = field.Tag.Get("default")
+		}
+
+		if raw == "" {
+			if field.Tag.Get("required") == "true" {
+				cfgErr.Missing = append(cfgErr.Missing, key)
+			}
+			continue
+		}
+
+		err := setField(fieldValue, raw)
+		if err != nil {
+			cfgErr.Invalid = append(cfgErr.Invalid, fmt.Sprintf("%s=%q: %v", key, raw, err))
+		}
+	}
+}
+
+// setDefaults fills the unset fields of the struct v with their default
+// tag values. Booleans are left as they are, since false cannot be told
+// apart from unset.
+func setDefaults(v reflect.Value) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Tag.Get("env") == "" {
+			if field.Type.Kind() == reflect.Struct {
+				setDefaults(fieldValue)
+			}
+			continue
+		}
+
+		raw := field.Tag.Get("default")
+		if raw == "" || field.Type.Kind() == reflect.Bool || !fieldValue.IsZero() {
+			continue
+		}
+
+		// the defaults are part of the source, and always parse
+		_ = setField(fieldValue, raw)
+	}
+}
+
+func setField(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// parseDuration accepts a Go duration string, or a bare integer which is
+// treated as a number of seconds
+func parseDuration(raw string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("must be a duration such as 30s or 5m")
+	}
+	return d, nil
+}
@@ -0,0 +1,132 @@
+package celeritas
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeEnvFiles creates env files in a temporary directory and makes
+// sure every key they define is removed from the environment afterwards
+func writeEnvFiles(t *testing.T, files map[string]string, keys ...string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, key := range keys {
+		key := key
+		_ = os.Unsetenv(key)
+		t.Cleanup(func() { _ = os.Unsetenv(key) })
+	}
+
+	return dir
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		".env":         "APP_NAME=test\nAPP_ENV=staging\nPORT=4000\nDEBUG=true\nCOOKIE_LIFETIME=60\nSHUTDOWN_TIMEOUT=5\n",
+		".env.staging": "PORT=5000\n",
+	}, "APP_NAME", "APP_ENV", "PORT", "DEBUG", "COOKIE_LIFETIME", "SHUTDOWN_TIMEOUT", "CSP", "CSP_REPORT_ONLY")
+
+	t.Setenv("APP_NAME", "from-environment")
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.AppName != "from-environment" {
+		t.Errorf("real environment should win over .env; got app name %q", cfg.AppName)
+	}
+	if cfg.Port != 5000 {
+		t.Errorf(".env.staging should override .env; got port %d", cfg.Port)
+	}
+	if !cfg.Debug {
+		t.Error("expected debug to be true")
+	}
+	if cfg.Cookie.Lifetime != 60 {
+		t.Errorf("expected cookie lifetime 60, got %d", cfg.Cookie.Lifetime)
+	}
+	if cfg.Cookie.Name != "celeritas" {
+		t.Errorf("expected default cookie name, got %q", cfg.Cookie.Name)
+	}
+	if cfg.ShutdownTimeout != 5*time.Second {
+		t.Errorf("expected shutdown timeout of 5s, got %s", cfg.ShutdownTimeout)
+	}
+	if strings.Contains(cfg.Security.CSP, "https:") {
+		t.Errorf("default CSP should not allow other origins: %q", cfg.Security.CSP)
+	}
+	if !cfg.Security.CSPReportOnly {
+		t.Error("default CSP should only be reported on")
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := writeEnvFiles(t, map[string]string{
+		".env": "PORT=abc\nDATABASE_TYPE=postgres\nKEY=short\n",
+	}, "PORT", "DATABASE_TYPE", "KEY")
+
+	_, err := LoadConfig(dir)
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected a *ConfigError, got %v", err)
+	}
+
+	for _, key := range []string{"DATABASE_HOST", "DATABASE_USER", "DATABASE_NAME"} {
+		if !contains(cfgErr.Missing, key) {
+			t.Errorf("expected %s to be reported missing; got %v", key, cfgErr.Missing)
+		}
+	}
+
+	if len(cfgErr.Invalid) != 3 {
+		t.Errorf("expected PORT, port range and KEY to be reported invalid; got %v", cfgErr.Invalid)
+	}
+}
+
+func TestConfig_Extend(t *testing.T) {
+	writeEnvFiles(t, nil, "SMTP_HOST", "SMTP_PORT")
+	t.Setenv("SMTP_HOST", "mail.example.com")
+
+	var mail struct {
+		Host string `env:"SMTP_HOST" required:"true"`
+		Port int    `env:"SMTP_PORT" default:"1025"`
+	}
+
+	cfg := &Config{}
+	err := cfg.Extend("mail", &mail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mail.Host != "mail.example.com" || mail.Port != 1025 {
+		t.Errorf("unexpected mail section: %+v", mail)
+	}
+
+	if _, ok := cfg.Section("mail"); !ok {
+		t.Error("mail section was not registered")
+	}
+
+	var missing struct {
+		Token string `env:"SOME_UNSET_TOKEN" required:"true"`
+	}
+	if err := cfg.Extend("missing", &missing); err == nil {
+		t.Error("expected an error for a missing required key")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
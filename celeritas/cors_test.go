@@ -0,0 +1,68 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCeleritas_CORS(t *testing.T) {
+	c := &Celeritas{Config: Config{CORS: CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           600,
+		Paths:            []string{"/api/*"},
+	}}}
+
+	reached := false
+	handler := c.CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+
+	var tests = []struct {
+		name          string
+		method        string
+		target        string
+		origin        string
+		preflight     bool
+		allowOrigin   string
+		expectReached bool
+	}{
+		{"preflight", "OPTIONS", "/api/users", "https://app.example.com", true, "https://app.example.com", false},
+		{"request", "POST", "/api/users", "https://app.example.com", false, "https://app.example.com", true},
+		{"other origin", "OPTIONS", "/api/users", "https://example.org", true, "", false},
+		{"wildcard needs a subdomain", "GET", "/api/users", "https://.example.com", false, "", true},
+		{"outside api", "OPTIONS", "/users", "https://app.example.com", true, "", true},
+	}
+
+	for _, e := range tests {
+		reached = false
+		r := httptest.NewRequest(e.method, e.target, nil)
+		r.Header.Set("Origin", e.origin)
+		if e.preflight {
+			r.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != e.allowOrigin {
+			t.Errorf("%s: expected Access-Control-Allow-Origin %q, got %q", e.name, e.allowOrigin, got)
+		}
+		if reached != e.expectReached {
+			t.Errorf("%s: expected handler reached to be %v", e.name, e.expectReached)
+		}
+		if e.name == "preflight" {
+			if w.Code != http.StatusNoContent {
+				t.Errorf("%s: expected 204, got %d", e.name, w.Code)
+			}
+			if w.Header().Get("Access-Control-Allow-Methods") != "GET, POST" || w.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Errorf("%s: unexpected preflight headers %v", e.name, w.Header())
+			}
+			if w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Errorf("%s: expected credentials to be allowed", e.name)
+			}
+		}
+	}
+}
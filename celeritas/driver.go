@@ -1,16 +1,38 @@
 package celeritas
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	_ "github.com/jackc/pgconn"
 	_ "github.com/jackc/pgx/v4"
 	_ "github.com/jackc/pgx/v4/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// the delays between attempts to connect to the database at startup
+const (
+	connectBackoff    = 250 * time.Millisecond
+	maxConnectBackoff = 5 * time.Second
 )
 
 func (c *Celeritas) OpenDB(dbType, dsn string) (*sql.DB, error) {
-	if dbType == "postgres" || dbType == "posgresql" {
+	switch dbType {
+	case "postgres", "postgresql", "posgresql":
 		dbType = "pgx"
+	case "mariadb":
+		dbType = "mysql"
+	case "sqlite3":
+		dbType = "sqlite"
 	}
 
 	db, err := sql.Open(dbType, dsn)
@@ -19,9 +41,140 @@ func (c *Celeritas) OpenDB(dbType, dsn string) (*sql.DB, error) {
 		return nil, err
 	}
 
+	db.SetMaxOpenConns(c.Config.Database.MaxOpenConns)
+	db.SetMaxIdleConns(c.Config.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(c.Config.Database.ConnMaxLifetime)
+
+	// every connection to an in-memory sqlite database gets its own,
+	// empty, database, so only ever use one, and never let it be closed
+	if dbType == "sqlite" && c.Config.Database.Name == ":memory:" {
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
 	err = db.Ping()
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 	return db, nil
 }
+
+// connectDB opens the configured database, retrying with exponential
+// backoff for up to DATABASE_CONNECT_TIMEOUT so that the application can
+// start alongside a database which is still starting up
+func (c *Celeritas) connectDB(dsn string) (*sql.DB, error) {
+	deadline := time.Now().Add(c.Config.Database.ConnectTimeout)
+	backoff := connectBackoff
+
+	for {
+		db, err := c.OpenDB(c.Config.Database.Type, dsn)
+		if err == nil {
+			return db, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+
+		c.Logger.Warn("database not ready, retrying", "error", err, "retry_in", backoff)
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
+
+// mysqlDSN builds a go-sql-driver/mysql DSN. Times are parsed into
+// time.Time in UTC, and DATABASE_SSL_MODE is translated to the driver's
+// tls parameter.
+func (c *Celeritas) mysqlDSN(db DatabaseConfig) string {
+	port := db.Port
+	if port == 0 {
+		port = 3306
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.User = db.User
+	cfg.Passwd = db.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(db.Host, strconv.Itoa(port))
+	cfg.DBName = db.Name
+	cfg.Collation = db.Collation
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	cfg.Timeout = 5 * time.Second
+
+	switch db.SSLMode {
+	case "disable", "":
+	case "prefer", "preferred":
+		cfg.TLSConfig = "preferred"
+	case "require":
+		cfg.TLSConfig = "skip-verify"
+	default:
+		cfg.TLSConfig = "true"
+	}
+
+	// without a usable root certificate the system roots are used, and the
+	// connection fails to verify the server
+	if db.SSLRootCert != "" && cfg.TLSConfig == "true" {
+		name := "celeritas-" + db.Host
+		err := c.registerMySQLTLS(name, db)
+		if err == nil {
+			cfg.TLSConfig = name
+		} else if c.Logger != nil {
+			c.Logger.Error("database tls", "error", err)
+		}
+	}
+
+	return cfg.FormatDSN()
+}
+
+// registerMySQLTLS registers, under name, a TLS configuration for db.Host
+// which trusts the certificate authority in DATABASE_SSL_ROOT_CERT
+func (c *Celeritas) registerMySQLTLS(name string, db DatabaseConfig) error {
+	pem, err := os.ReadFile(c.databasePath(db.SSLRootCert))
+	if err != nil {
+		return err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in %s", db.SSLRootCert)
+	}
+
+	return mysql.RegisterTLSConfig(name, &tls.Config{
+		RootCAs:    pool,
+		ServerName: db.Host,
+		MinVersion: tls.VersionTLS12,
+	})
+}
+
+// sqliteDSN builds a modernc.org/sqlite DSN which enforces foreign keys
+// and waits, rather than failing, when the database is locked
+func (c *Celeritas) sqliteDSN(db DatabaseConfig) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+
+	name := db.Name
+	if name != ":memory:" {
+		params.Add("_pragma", "journal_mode(WAL)")
+		name = c.databasePath(name)
+	}
+
+	return "file:" + name + "?" + params.Encode()
+}
+
+// databasePath resolves a path from the database configuration relative
+// to the application root
+func (c *Celeritas) databasePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.RootPath, path)
+}
@@ -0,0 +1,133 @@
+package celeritas
+
+import (
+	"bytes"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/alexedwards/scs/sqlite3store"
+)
+
+func TestCeleritas_BuildDSN(t *testing.T) {
+	var tests = []struct {
+		name     string
+		database DatabaseConfig
+		contains []string
+	}{
+		{"postgres", DatabaseConfig{Type: "postgres", Host: "db", Port: 5432, User: "u", Name: "app", SSLMode: "disable"}, []string{"host=db", "port=5432", "sslmode=disable"}},
+		{"mysql", DatabaseConfig{Type: "mysql", Host: "db", User: "u", Password: "p", Name: "app", SSLMode: "disable", Collation: "utf8mb4_unicode_ci"}, []string{"u:p@tcp(db:3306)/app?", "parseTime=true", "collation=utf8mb4_unicode_ci"}},
+		{"mariadb with tls", DatabaseConfig{Type: "mariadb", Host: "db", Port: 3307, User: "u", Name: "app", SSLMode: "require"}, []string{"tcp(db:3307)", "tls=skip-verify"}},
+		{"sqlite", DatabaseConfig{Type: "sqlite", Name: "data/app.db"}, []string{"file:" + filepath.Join("/app", "data", "app.db"), "foreign_keys", "journal_mode"}},
+	}
+
+	for _, e := range tests {
+		c := &Celeritas{RootPath: "/app", Config: Config{Database: e.database}}
+		dsn := c.BuildDSN()
+		for _, want := range e.contains {
+			if !strings.Contains(dsn, want) {
+				t.Errorf("%s: expected %s in dsn %s", e.name, want, dsn)
+			}
+		}
+	}
+}
+
+func TestNewApp_SQLite(t *testing.T) {
+	app, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{
+			Port:        4000,
+			Renderer:    "jet",
+			SessionType: "sqlite",
+			Database:    DatabaseConfig{Type: "sqlite", Name: "app.db", SSLMode: "disable", MaxOpenConns: 3, MaxIdleConns: 3},
+		}),
+		WithViews(fstest.MapFS{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.DB.Pool.Close()
+
+	if app.DB.Writer != app.DB.Pool || app.DB.Reader != app.DB.Writer {
+		t.Error("without a replica, reads and writes should share the pool")
+	}
+
+	if max := app.DB.Pool.Stats().MaxOpenConnections; max != 3 {
+		t.Errorf("expected at most 3 open connections, got %d", max)
+	}
+
+	if _, ok := app.Session.Store.(*sqlite3store.SQLite3Store); !ok {
+		t.Errorf("expected the sqlite session store, got %T", app.Session.Store)
+	}
+
+	_, err = app.DB.Pool.Exec("create table parents (id integer primary key); create table children (parent_id integer references parents(id))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = app.DB.Pool.Exec("insert into children (parent_id) values (1)")
+	if err == nil {
+		t.Error("foreign keys are not enforced")
+	}
+}
+
+func TestCeleritas_OpenDBMemory(t *testing.T) {
+	c := &Celeritas{Config: Config{Database: DatabaseConfig{
+		Type:            "sqlite",
+		Name:            ":memory:",
+		MaxOpenConns:    25,
+		MaxIdleConns:    0,
+		ConnMaxLifetime: 10 * time.Millisecond,
+	}}}
+
+	db, err := c.OpenDB("sqlite", c.sqliteDSN(c.Config.Database))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("create table widgets (name text); insert into widgets (name) values ('sprocket')")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	var name string
+	err = db.QueryRow("select name from widgets").Scan(&name)
+	if err != nil || name != "sprocket" {
+		t.Errorf("in-memory database was lost: %q, %v", name, err)
+	}
+}
+
+func TestCeleritas_connectDB(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Celeritas{
+		Logger: slog.New(slog.NewTextHandler(&buf, nil)),
+		Config: Config{Database: DatabaseConfig{
+			Type:           "postgres",
+			Host:           "127.0.0.1",
+			Port:           1,
+			User:           "nobody",
+			Name:           "nothing",
+			SSLMode:        "disable",
+			ConnectTimeout: time.Second,
+		}},
+	}
+
+	start := time.Now()
+	_, err := c.connectDB(c.BuildDSN())
+	if err == nil {
+		t.Fatal("expected an error when the database is unreachable")
+	}
+
+	if time.Since(start) < connectBackoff {
+		t.Error("gave up without retrying")
+	}
+	if !strings.Contains(buf.String(), "database not ready, retrying") {
+		t.Errorf("retries were not logged: %s", buf.String())
+	}
+}
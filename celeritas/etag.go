@@ -0,0 +1,305 @@
+package celeritas
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ETag returns an entity tag for data. Strong tags promise byte for byte
+// identical responses; weak tags only promise equivalent ones, and are the
+// right choice when the response may be compressed on the way out.
+func ETag(data []byte, weak bool) string {
+	sum := sha256.Sum256(data)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// NotModified sets the ETag and Last-Modified headers, either of which may
+// be empty, and answers 304 Not Modified when the request's If-None-Match
+// or If-Modified-Since shows the client already has this version. Handlers
+// return straight away when it reports true.
+//
+//	if app.NotModified(w, r, celeritas.ETag(out, false), post.UpdatedAt) {
+//		return
+//	}
+func (c *Celeritas) NotModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if !notModified(r, etag, modified) {
+		return false
+	}
+
+	writeNotModified(w)
+	return true
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is
+// no If-None-Match, as RFC 9110 requires
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatch(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatch uses the weak comparison, which is the one defined for
+// If-None-Match
+func etagMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified answers 304, dropping the headers which describe a body
+func writeNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// maxConditionalBody is the most ConditionalGet holds back to tag; larger
+// responses are streamed untagged
+const maxConditionalBody = 1 << 20
+
+// ConditionalGet buffers successful GET and HEAD responses of HTML or
+// JSON, tags them with a weak ETag (unless the handler set its own ETag)
+// and answers 304 Not Modified when the client's copy is still current.
+// Other content, such as files and downloads, responses over 1MB,
+// responses marked no-store, and handlers which flush, are passed through
+// untouched. Pages which carry a CSP nonce differ on every request and
+// never match, so use it for routes whose responses do not, e.g.
+//
+//	a.App.Routes.With(a.App.ConditionalGet).Get("/api/widgets", handler)
+func (c *Celeritas) ConditionalGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &etagWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		if ew.passthrough {
+			return
+		}
+
+		if ew.status == 0 {
+			ew.status = http.StatusOK
+		}
+
+		h := w.Header()
+		if !strings.Contains(h.Get("Cache-Control"), "no-store") {
+			etag := h.Get("ETag")
+			if etag == "" {
+				etag = ETag(ew.buf.Bytes(), true)
+			}
+
+			var modified time.Time
+			if lm := h.Get("Last-Modified"); lm != "" {
+				modified, _ = http.ParseTime(lm)
+			}
+
+			if c.NotModified(w, r, etag, modified) {
+				return
+			}
+		}
+
+		w.WriteHeader(ew.status)
+		_, _ = ew.buf.WriteTo(w)
+	})
+}
+
+// etagWriter holds back the response so that it can be tagged, until it
+// turns out not to be a response worth tagging
+type etagWriter struct {
+	http.ResponseWriter
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+func (ew *etagWriter) WriteHeader(status int) {
+	if ew.passthrough {
+		ew.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if ew.status != 0 {
+		return
+	}
+
+	ew.status = status
+	if status != http.StatusOK || !taggable(ew.Header().Get("Content-Type")) {
+		ew.startPassthrough()
+	}
+}
+
+func (ew *etagWriter) Write(p []byte) (int, error) {
+	if ew.status == 0 {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if !ew.passthrough && ew.buf.Len()+len(p) > maxConditionalBody {
+		ew.startPassthrough()
+	}
+	if ew.passthrough {
+		return ew.ResponseWriter.Write(p)
+	}
+	return ew.buf.Write(p)
+}
+
+// startPassthrough sends what has been held back and stops buffering
+func (ew *etagWriter) startPassthrough() {
+	ew.passthrough = true
+	if ew.status != 0 {
+		ew.ResponseWriter.WriteHeader(ew.status)
+	}
+	_, _ = ew.buf.WriteTo(ew.ResponseWriter)
+}
+
+// taggable reports whether a response is rendered HTML or JSON. An empty
+// Content-Type is left for net/http to sniff, which handlers writing HTML
+// often rely on.
+func taggable(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return mediaType == "" ||
+		mediaType == "text/html" ||
+		mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// Flush gives up on tagging the response, since a streaming handler wants
+// what it has written so far to reach the client
+func (ew *etagWriter) Flush() {
+	if !ew.passthrough {
+		ew.startPassthrough()
+	}
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets websocket handlers take over the connection
+func (ew *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := ew.ResponseWriter.(http.Hijacker); ok {
+		ew.passthrough = true
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (ew *etagWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
+// FileServer serves static files from root. Fingerprinted names from the
+// asset manifest (see BuildAssets) are served from the file they were
+// built from, and cached by browsers for a year, since a change to the
+// file changes its name; a name whose hash no longer matches the file is
+// not found. Other files must be revalidated, which is cheap
+// since every file carries an ETag and Last-Modified, and unchanged files
+// are answered with 304.
+//
+//	a.App.Routes.Handle("/public/*", http.StripPrefix("/public", a.App.FileServer(http.Dir("./public"))))
+func (c *Celeritas) FileServer(root http.FileSystem) http.Handler {
+	fileServer := http.FileServer(root)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+
+		source, immutable := c.assetSource(strings.TrimPrefix(name, "/"))
+		if immutable {
+			// a manifest older than the file would have it cached for a
+			// year under the wrong name
+			if !c.assetCurrent(root, strings.TrimPrefix(name, "/"), source) {
+				http.NotFound(w, r)
+				return
+			}
+			name = "/" + source
+
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = name
+			r2.URL.RawPath = ""
+			r = r2
+		}
+
+		if f, err := root.Open(name); err == nil {
+			info, err := f.Stat()
+			if err == nil && !info.IsDir() {
+				// http.ServeContent honours If-None-Match against this tag
+				w.Header().Set("ETag", c.fileETag(name, f, info))
+
+				if immutable {
+					w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+				} else {
+					w.Header().Set("Cache-Control", "public, no-cache")
+				}
+			}
+			f.Close()
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// fileETag tags a file by its modification time and size. Embedded files
+// have no modification time, so they are tagged by a hash of their
+// contents instead, worked out once since they cannot change.
+func (c *Celeritas) fileETag(name string, f http.File, info fs.FileInfo) string {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+	}
+
+	if etag, ok := c.fileETags.Load(name); ok {
+		return etag.(string)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	c.fileETags.Store(name, etag)
+	return etag
+}
@@ -0,0 +1,136 @@
+package celeritas
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCeleritas_ConditionalGet(t *testing.T) {
+	c := &Celeritas{}
+	handler := c.ConditionalGet(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = c.WriteJSON(w, http.StatusOK, map[string]string{"hello": "world"})
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("expected a tagged 200, got %d %q", w.Code, etag)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected an empty 304, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `W/"stale"`)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for a stale tag, got %d", w.Code)
+	}
+}
+
+func TestCeleritas_ConditionalGetPassthrough(t *testing.T) {
+	c := &Celeritas{}
+	large := strings.Repeat("x", maxConditionalBody+1)
+
+	var tests = []struct {
+		name    string
+		handler http.HandlerFunc
+		body    string
+	}{
+		{"download", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = w.Write([]byte("%PDF"))
+		}, "%PDF"},
+		{"large_page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(large[:10]))
+			_, _ = w.Write([]byte(large[10:]))
+		}, large},
+		{"not_found", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}, "404 page not found\n"},
+	}
+
+	for _, e := range tests {
+		w := httptest.NewRecorder()
+		c.ConditionalGet(e.handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Header().Get("ETag") != "" {
+			t.Errorf("%s: expected the response to pass through untagged", e.name)
+		}
+		if w.Body.String() != e.body {
+			t.Errorf("%s: body changed on the way through (%d bytes)", e.name, w.Body.Len())
+		}
+	}
+}
+
+func TestCeleritas_NotModified(t *testing.T) {
+	c := &Celeritas{}
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	w := httptest.NewRecorder()
+	if !c.NotModified(w, r, "", modified) || w.Code != http.StatusNotModified {
+		t.Error("expected 304 for an unchanged resource")
+	}
+
+	w = httptest.NewRecorder()
+	if c.NotModified(w, r, "", modified.Add(time.Hour)) {
+		t.Error("expected no 304 for a resource changed since")
+	}
+
+	if ETag([]byte("a"), false) == ETag([]byte("b"), false) || ETag([]byte("a"), true)[:2] != "W/" {
+		t.Error("unexpected ETag values")
+	}
+}
+
+func TestCeleritas_FileServer(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.css", "app.3f9a2c1d.css"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("body{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &Celeritas{}
+	handler := c.FileServer(http.Dir(dir))
+
+	var tests = []struct {
+		name         string
+		cacheControl string
+	}{
+		{"/app.css", "public, no-cache"},
+		// only names from the asset manifest are immutable, whatever they
+		// look like
+		{"/app.3f9a2c1d.css", "public, no-cache"},
+	}
+
+	for _, e := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", e.name, nil))
+		if got := w.Header().Get("Cache-Control"); got != e.cacheControl {
+			t.Errorf("%s: expected Cache-Control %q, got %q", e.name, e.cacheControl, got)
+		}
+
+		r := httptest.NewRequest("GET", e.name, nil)
+		r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if body, _ := io.ReadAll(w.Body); w.Code != http.StatusNotModified || len(body) != 0 {
+			t.Errorf("%s: expected 304, got %d", e.name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package celeritas
+
+import (
+	"context"
+
+	"github.com/leetrent/celeritas/render"
+)
+
+// Flash keeps a message in the session until the next page is rendered,
+// where it appears as .Flash, .Error or .Warning for those levels, and in
+// .Flashes, which holds every message with its level.
+//
+//	app.Flash(r.Context(), render.LevelError, "Invalid password")
+//	http.Redirect(w, r, app.URL("login"), http.StatusSeeOther)
+func (c *Celeritas) Flash(ctx context.Context, level, message string) {
+	render.AddFlash(c.Session, ctx, level, message)
+}
+
+// Flashes returns, and removes, the messages waiting in the session, for
+// responses which are not rendered from a template, such as JSON
+//
+//	app.WriteJSON(w, http.StatusOK, map[string]interface{}{"flashes": app.Flashes(r.Context())})
+func (c *Celeritas) Flashes(ctx context.Context) []render.FlashMessage {
+	return render.PopFlashes(c.Session, ctx)
+}
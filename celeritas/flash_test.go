@@ -0,0 +1,76 @@
+package celeritas
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/leetrent/celeritas/render"
+)
+
+func TestCeleritas_Flash(t *testing.T) {
+	app, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{Port: 4000, Renderer: "jet", SessionType: "cookie", Cookie: CookieConfig{Name: "celeritas", Lifetime: 60}}),
+		WithViews(fstest.MapFS{
+			"login.jet": &fstest.MapFile{Data: []byte(
+				`[{{ .Error }}][{{ .Warning }}]{{ range .Flashes }}({{ .Level }}:{{ .Message }}){{ end }}`)},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Routes.Get("/flash", func(w http.ResponseWriter, r *http.Request) {
+		app.Flash(r.Context(), render.LevelError, "Invalid password")
+		app.Flash(r.Context(), render.LevelError, "Try again")
+		app.Flash(r.Context(), "info", "Caps lock is on")
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	})
+	app.Routes.Get("/login", func(w http.ResponseWriter, r *http.Request) {
+		if err := app.Render.Page(w, r, "login", nil, nil); err != nil {
+			t.Error(err)
+		}
+	})
+	app.Routes.Get("/api/flashes", func(w http.ResponseWriter, r *http.Request) {
+		_ = app.WriteJSON(w, http.StatusOK, app.Flashes(r.Context()))
+	})
+
+	var cookies []*http.Cookie
+	get := func(target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", target, nil)
+		for _, cookie := range cookies {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		app.Routes.ServeHTTP(w, r)
+		if set := w.Result().Cookies(); len(set) > 0 {
+			cookies = set
+		}
+		return w
+	}
+
+	get("/flash")
+	expected := "[Invalid password Try again][](error:Invalid password)(error:Try again)(info:Caps lock is on)"
+	if got := get("/login").Body.String(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := get("/login").Body.String(); got != "[][]" {
+		t.Errorf("flash messages were shown twice: %q", got)
+	}
+
+	get("/flash")
+	var flashes []render.FlashMessage
+	if err := json.NewDecoder(get("/api/flashes").Body).Decode(&flashes); err != nil {
+		t.Fatal(err)
+	}
+	if len(flashes) != 3 || flashes[2].Level != "info" {
+		t.Errorf("unexpected flash messages %+v", flashes)
+	}
+	if got := get("/login").Body.String(); strings.Contains(got, "Invalid password") {
+		t.Errorf("flash messages returned as JSON were shown again: %q", got)
+	}
+}
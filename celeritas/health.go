@@ -0,0 +1,199 @@
+package celeritas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gomodule/redigo/redis"
+)
+
+const defaultHealthTimeout = 2 * time.Second
+
+// HealthCheck reports whether a component the application depends on is
+// usable. The context expires after HEALTH_TIMEOUT.
+type HealthCheck func(ctx context.Context) error
+
+// HealthReport is the JSON body returned by the readiness endpoint
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Checks map[string]HealthResult `json:"checks,omitempty"`
+}
+
+// HealthResult is the outcome of a single health check
+type HealthResult struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// AddHealthCheck registers a custom check which is run, alongside the
+// database, redis and badger checks, by the readiness endpoint
+func (c *Celeritas) AddHealthCheck(name string, check HealthCheck) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	c.healthChecks = append(c.healthChecks, namedHealthCheck{name, check})
+}
+
+// HealthHandler serves /live, which only reports that the process is
+// running, and /ready, which runs every health check and answers 503 if
+// any of them fails. It is mounted at HEALTH_PATH by default.
+func (c *Celeritas) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			c.ErrorStatus(w, http.StatusMethodNotAllowed)
+			return
+		}
+
+		switch path.Base(r.URL.Path) {
+		case "live":
+			_ = c.WriteJSON(w, http.StatusOK, HealthReport{Status: "ok"})
+		case "ready":
+			report := c.CheckHealth(r.Context())
+
+			status := http.StatusOK
+			if report.Status != "ok" {
+				status = http.StatusServiceUnavailable
+			}
+
+			_ = c.WriteJSON(w, status, report, http.Header{"Cache-Control": {"no-store"}})
+		default:
+			c.ErrorStatus(w, http.StatusNotFound)
+		}
+	})
+}
+
+// CheckHealth runs every health check concurrently and reports on each
+func (c *Celeritas) CheckHealth(ctx context.Context) HealthReport {
+	timeout := c.Config.Health.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	checks := c.builtinHealthChecks()
+	c.healthMu.Lock()
+	checks = append(checks, c.healthChecks...)
+	c.healthMu.Unlock()
+
+	report := HealthReport{Status: "ok", Checks: make(map[string]HealthResult, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, hc := range checks {
+		wg.Add(1)
+		go func(hc namedHealthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := runHealthCheck(ctx, hc.check)
+			result := HealthResult{Status: "ok", Duration: time.Since(start).String()}
+			if err != nil {
+				result.Status = "failed"
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[hc.name] = result
+			if err != nil {
+				report.Status = "unavailable"
+			}
+		}(hc)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// runHealthCheck stops waiting for a check once ctx expires, for checks
+// which do not honour the context themselves
+func runHealthCheck(ctx context.Context, check HealthCheck) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// builtinHealthChecks returns a check for each configured backend
+func (c *Celeritas) builtinHealthChecks() []namedHealthCheck {
+	var checks []namedHealthCheck
+
+	if c.DB.Pool != nil {
+		checks = append(checks, namedHealthCheck{"database", c.DB.Pool.PingContext})
+	}
+
+	if c.DB.Reader != nil && c.DB.Reader != c.DB.Pool {
+		checks = append(checks, namedHealthCheck{"database_replica", c.DB.Reader.PingContext})
+	}
+
+	if c.redisPool != nil {
+		checks = append(checks, namedHealthCheck{"redis", c.pingRedis})
+	}
+
+	if c.badgerConn != nil {
+		checks = append(checks, namedHealthCheck{"badger", c.pingBadger})
+	}
+
+	return checks
+}
+
+func (c *Celeritas) pingRedis(ctx context.Context) error {
+	conn, err := c.redisPool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = redis.DoContext(conn, ctx, "PING")
+	return err
+}
+
+func (c *Celeritas) pingBadger(ctx context.Context) error {
+	if c.badgerConn.IsClosed() {
+		return errors.New("badger is closed")
+	}
+
+	return c.badgerConn.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte("celeritas:health"))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Health serves the health endpoints ahead of the session and CSRF
+// middleware, so that probes neither create sessions nor need a token
+func (c *Celeritas) Health(next http.Handler) http.Handler {
+	prefix := strings.TrimSuffix(c.Config.Health.Path, "/")
+	health := c.HealthHandler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix+"/live" || r.URL.Path == prefix+"/ready" {
+			health.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
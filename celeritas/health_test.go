@@ -0,0 +1,82 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCeleritas_Health(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	app, err := NewApp(
+		WithConfig(&Config{
+			Port:        4000,
+			Renderer:    "jet",
+			SessionType: "cookie",
+			Health:      HealthConfig{Enabled: true, Path: "/status", Timeout: 100 * time.Millisecond},
+		}),
+		WithDB(db),
+		WithViews(fstest.MapFS{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// chi only runs middleware once a route has been registered
+	app.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	var tests = []struct {
+		name           string
+		check          HealthCheck
+		expectedStatus int
+	}{
+		{"healthy", func(ctx context.Context) error { return nil }, http.StatusOK},
+		{"failing", func(ctx context.Context) error { return errors.New("queue is full") }, http.StatusServiceUnavailable},
+		{"slow", func(ctx context.Context) error { time.Sleep(time.Second); return nil }, http.StatusServiceUnavailable},
+	}
+
+	for _, e := range tests {
+		app.healthChecks = nil
+		app.AddHealthCheck("custom", e.check)
+
+		w := httptest.NewRecorder()
+		app.Routes.ServeHTTP(w, httptest.NewRequest("GET", "/status/ready", nil))
+
+		if w.Code != e.expectedStatus {
+			t.Errorf("%s: expected status %d, got %d", e.name, e.expectedStatus, w.Code)
+		}
+
+		var report HealthReport
+		err := json.Unmarshal(w.Body.Bytes(), &report)
+		if err != nil {
+			t.Fatalf("%s: %s", e.name, err)
+		}
+
+		if report.Checks["database"].Status != "ok" {
+			t.Errorf("%s: expected the database check to pass, got %+v", e.name, report.Checks["database"])
+		}
+		if _, ok := report.Checks["custom"]; !ok {
+			t.Errorf("%s: custom check missing from report", e.name)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	app.Routes.ServeHTTP(w, httptest.NewRequest("GET", "/status/live", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected live to answer 200, got %d", w.Code)
+	}
+	if len(w.Result().Cookies()) > 0 {
+		t.Error("health endpoints should not set session or CSRF cookies")
+	}
+}
@@ -0,0 +1,102 @@
+package celeritas
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/leetrent/celeritas/logging"
+)
+
+// RequestLogger returns the logger for the current request, which adds
+// the request id, route pattern and authenticated user id to every line.
+// Outside of a request it returns c.Logger.
+func (c *Celeritas) RequestLogger(r *http.Request) *slog.Logger {
+	return logging.FromContext(r.Context(), c.Logger)
+}
+
+// LogRequests stores a request scoped logger in the request context (see
+// RequestLogger) and writes an access log line, at debug level, once the
+// request has been handled
+func (c *Celeritas) LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		logger := slog.New(requestHandler{
+			Handler: c.Logger.Handler(),
+			attrs: []slog.Attr{
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.Any("route", routePattern{r.Context()}),
+				slog.Any("user_id", sessionUser{c, r.Context()}),
+			},
+		})
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(logging.WithLogger(r.Context(), logger)))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		logger.Debug("request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// requestHandler adds the request attributes to each record as it is
+// handled. Handlers resolve attributes passed to With straight away, which
+// is too early for the route and user, so they are added here instead.
+type requestHandler struct {
+	slog.Handler
+	attrs []slog.Attr
+}
+
+func (h requestHandler) Handle(ctx context.Context, record slog.Record) error {
+	record = record.Clone()
+	record.AddAttrs(h.attrs...)
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestHandler{h.Handler.WithAttrs(attrs), h.attrs}
+}
+
+func (h requestHandler) WithGroup(name string) slog.Handler {
+	return requestHandler{h.Handler.WithGroup(name), h.attrs}
+}
+
+// routePattern resolves to the chi route pattern when a line is logged,
+// since routing happens after the logger is created
+type routePattern struct {
+	ctx context.Context
+}
+
+func (p routePattern) LogValue() slog.Value {
+	if rctx := chi.RouteContext(p.ctx); rctx != nil {
+		return slog.StringValue(rctx.RoutePattern())
+	}
+	return slog.StringValue("")
+}
+
+// sessionUser resolves to the authenticated user's id when a line is
+// logged, and is omitted for anonymous requests
+type sessionUser struct {
+	c   *Celeritas
+	ctx context.Context
+}
+
+func (u sessionUser) LogValue() slog.Value {
+	if u.c.Session == nil || !u.c.Session.Exists(u.ctx, "userID") {
+		return slog.GroupValue()
+	}
+	return slog.AnyValue(u.c.Session.Get(u.ctx, "userID"))
+}
@@ -0,0 +1,47 @@
+package celeritas
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestCeleritas_LogRequests(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Celeritas{
+		Logger: slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+
+	mux := chi.NewRouter()
+	mux.Use(middleware.RequestID)
+	mux.Use(c.LogRequests)
+	mux.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		c.RequestLogger(r).Info("showing user")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	r := httptest.NewRequest("GET", "/users/42", nil)
+	r.Header.Set("X-Request-Id", "abc-123")
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a handler line and an access line, got %q", buf.String())
+	}
+
+	for _, want := range []string{`"request_id":"abc-123"`, `"route":"/users/{id}"`, `"msg":"showing user"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("handler log line %s is missing %s", lines[0], want)
+		}
+	}
+
+	if !strings.Contains(lines[1], `"status":202`) {
+		t.Errorf("access log line %s is missing the status", lines[1])
+	}
+}
@@ -0,0 +1,204 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102T150405.000000000"
+
+// RotatingFile is an io.WriteCloser which appends to Filename and moves
+// it aside to a timestamped backup once it grows beyond MaxSize bytes or
+// was begun longer than MaxAge ago. Only the newest MaxBackups
+// backups are kept. A zero value for any of the limits disables it.
+type RotatingFile struct {
+	Filename   string
+	MaxSize    int64
+	MaxAge     time.Duration
+	MaxBackups int
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	started time.Time
+}
+
+// Write appends p to the log file, rotating it first if needed
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Rotate forces the current log file to be moved aside
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+func (f *RotatingFile) shouldRotate(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.MaxSize > 0 && f.size+next > f.MaxSize {
+		return true
+	}
+	if f.MaxAge > 0 && time.Since(f.started) > f.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (f *RotatingFile) open() error {
+	err := os.MkdirAll(filepath.Dir(f.Filename), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.started = f.startTime(info)
+	return nil
+}
+
+// startTime works out when the log file was begun, so that MaxAge holds
+// across restarts. A file is begun when the newest backup is moved aside,
+// and one which has never been rotated was begun no later than its last
+// write.
+func (f *RotatingFile) startTime(info os.FileInfo) time.Time {
+	if info.Size() == 0 {
+		return time.Now()
+	}
+
+	backups, err := f.backups()
+	if err == nil && len(backups) > 0 {
+		ext := filepath.Ext(f.Filename)
+		stamp := strings.TrimPrefix(backups[len(backups)-1], strings.TrimSuffix(f.Filename, ext)+"-")
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(stamp, ext))
+		if err == nil {
+			return t
+		}
+	}
+
+	return info.ModTime()
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+
+	if _, err := os.Stat(f.Filename); err == nil {
+		err = os.Rename(f.Filename, f.nextBackupName())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	return f.prune()
+}
+
+// backupName turns logs/app.log into logs/app-20220401T101500.000000000.log
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.Filename)
+	base := strings.TrimSuffix(f.Filename, ext)
+	return base + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// nextBackupName returns a backup name which is not taken yet and sorts
+// after all existing backups
+func (f *RotatingFile) nextBackupName() string {
+	t := time.Now().UTC()
+	name := f.backupName(t)
+	for {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+		t = t.Add(time.Nanosecond)
+		name = f.backupName(t)
+	}
+}
+
+// backups returns the existing backups of the log file, oldest first
+func (f *RotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(f.Filename)
+	pattern := strings.TrimSuffix(f.Filename, ext) + "-*" + ext
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (f *RotatingFile) prune() error {
+	if f.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > f.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
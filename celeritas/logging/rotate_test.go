@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_Write(t *testing.T) {
+	dir := t.TempDir()
+	f := &RotatingFile{
+		Filename:   filepath.Join(dir, "logs", "app.log"),
+		MaxSize:    10,
+		MaxBackups: 2,
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	current, err := os.ReadFile(f.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current) != "fourth\n" {
+		t.Errorf("unexpected contents of current log file: %q", current)
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups to be kept, got %d", len(backups))
+	}
+
+	newest, err := os.ReadFile(backups[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(newest) != "third\n" {
+		t.Errorf("unexpected contents of newest backup: %q", newest)
+	}
+}
+
+func TestRotatingFile_MaxAgeAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour)
+
+	// never rotated, last written two hours ago
+	f := &RotatingFile{Filename: filepath.Join(dir, "first.log"), MaxAge: time.Hour}
+	if err := os.WriteFile(f.Filename, []byte("before the restart\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(f.Filename, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("after the restart\n")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if backups, _ := f.backups(); len(backups) != 1 {
+		t.Errorf("expected a file last written two hours ago to be rotated, got %d backups", len(backups))
+	}
+
+	// begun two hours ago, when the newest backup was moved aside, and
+	// written to since
+	f = &RotatingFile{Filename: filepath.Join(dir, "second.log"), MaxAge: time.Hour}
+	if err := os.WriteFile(f.backupName(old.UTC()), []byte("older\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f.Filename, []byte("before the restart\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("after the restart\n")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if backups, _ := f.backups(); len(backups) != 2 {
+		t.Errorf("expected a file begun two hours ago to be rotated, got %d backups", len(backups))
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf strings.Builder
+	logger, err := New(Options{Level: "warn", Format: "json", File: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("ignored")
+	logger.Warn("kept", "user_id", 7)
+
+	if strings.Contains(buf.String(), "ignored") {
+		t.Error("info message logged at warn level")
+	}
+	if !strings.Contains(buf.String(), `"user_id":7`) {
+		t.Errorf("expected a json log line, got %q", buf.String())
+	}
+
+	if _, err := New(Options{Level: "loud"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,271 @@
+package celeritas
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leetrent/celeritas/render"
+)
+
+const maintenanceCookie = "celeritas_maintenance"
+
+// maintenanceCheckInterval is how often the Maintenance middleware looks
+// at the marker file again
+const maintenanceCheckInterval = time.Second
+
+// MaintenanceMode describes an application which has been taken down.
+// It is stored as JSON in tmp/down while the application is down.
+type MaintenanceMode struct {
+	// Secret, when set, lets a browser in by visiting /<secret>, which
+	// sets a bypass cookie and redirects to the home page.
+	Secret string `json:"secret,omitempty"`
+
+	// Retry is sent, in seconds, in the Retry-After header
+	Retry int `json:"retry,omitempty"`
+
+	Since time.Time `json:"since"`
+}
+
+// maintenanceFile is the marker file whose presence puts the application
+// into maintenance mode
+func maintenanceFile(rootPath string) string {
+	return filepath.Join(rootPath, "tmp", "down")
+}
+
+// Down puts the application into maintenance mode. Other running instances
+// pick the change up within a second.
+func (c *Celeritas) Down(mode MaintenanceMode) error {
+	if mode.Since.IsZero() {
+		mode.Since = time.Now().UTC()
+	}
+
+	out, err := json.MarshalIndent(mode, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	file := maintenanceFile(c.RootPath)
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(file, out, 0600)
+	c.maintenance.reset()
+	return err
+}
+
+// Up takes the application out of maintenance mode
+func (c *Celeritas) Up() error {
+	err := os.Remove(maintenanceFile(c.RootPath))
+	c.maintenance.reset()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// IsDown reports whether the application is in maintenance mode, and how
+func (c *Celeritas) IsDown() (*MaintenanceMode, bool) {
+	data, err := os.ReadFile(maintenanceFile(c.RootPath))
+	if err != nil {
+		return nil, false
+	}
+
+	// a marker which cannot be read still means the application is down
+	var mode MaintenanceMode
+	_ = json.Unmarshal(data, &mode)
+	return &mode, true
+}
+
+// maintenanceState remembers what the marker file said, so that it is
+// not read on every request
+type maintenanceState struct {
+	mu      sync.Mutex
+	checked time.Time
+	modTime time.Time
+	size    int64
+	mode    *MaintenanceMode
+}
+
+// reset makes the next request look at the marker file again
+func (s *maintenanceState) reset() {
+	s.mu.Lock()
+	s.checked = time.Time{}
+	s.mode = nil
+	s.mu.Unlock()
+}
+
+// maintenanceMode is IsDown, looking at the marker file at most once per
+// maintenanceCheckInterval and reading it only when it has changed, so
+// that down and up made elsewhere, e.g. by the CLI, show up within a second
+func (c *Celeritas) maintenanceMode() (*MaintenanceMode, bool) {
+	s := &c.maintenance
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.checked) < maintenanceCheckInterval {
+		return s.mode, s.mode != nil
+	}
+	s.checked = now
+
+	info, err := os.Stat(maintenanceFile(c.RootPath))
+	if err != nil {
+		s.mode = nil
+		return nil, false
+	}
+	if s.mode != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.mode, true
+	}
+
+	mode, down := c.IsDown()
+	if !down {
+		s.mode = nil
+		return nil, false
+	}
+	s.mode, s.modTime, s.size = mode, info.ModTime(), info.Size()
+	return mode, true
+}
+
+// Maintenance answers 503 Service Unavailable while the application is
+// down, except to allow-listed IP addresses and to browsers which have
+// the bypass cookie. It runs before the session is loaded, since the
+// session store may be what is being worked on.
+func (c *Celeritas) Maintenance(next http.Handler) http.Handler {
+	var allowed []*net.IPNet
+	for _, entry := range c.Config.Maintenance.AllowedIPs {
+		if network, ok := parseIPNet(entry); ok {
+			allowed = append(allowed, network)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mode, down := c.maintenanceMode()
+		if !down {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ip := net.ParseIP(clientIP(r)); ip != nil {
+			for _, network := range allowed {
+				if network.Contains(ip) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
+		if mode.Secret != "" {
+			token := maintenanceToken(mode.Secret)
+
+			if cookie, err := r.Cookie(maintenanceCookie); err == nil && subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(token)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.URL.Path == "/"+mode.Secret {
+				http.SetCookie(w, &http.Cookie{
+					Name:     maintenanceCookie,
+					Value:    token,
+					Path:     "/",
+					HttpOnly: true,
+					Secure:   c.Config.Cookie.Secure || c.Config.Secure,
+					SameSite: http.SameSiteLaxMode,
+					Expires:  time.Now().Add(12 * time.Hour),
+				})
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+				return
+			}
+		}
+
+		if mode.Retry > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(mode.Retry))
+		}
+		w.Header().Set("Cache-Control", "no-store")
+
+		c.serviceUnavailable(w, r, mode)
+	})
+}
+
+// serviceUnavailable writes the 503 response, from views/errors/503 when
+// the application has one
+func (c *Celeritas) serviceUnavailable(w http.ResponseWriter, r *http.Request, mode *MaintenanceMode) {
+	td := &render.TemplateData{
+		Port:       strconv.Itoa(c.Config.Port),
+		ServerName: c.Config.ServerName,
+		Secure:     c.Config.Secure,
+		CSPNonce:   render.CSPNonce(r),
+		Data:       map[string]interface{}{"retry": mode.Retry, "since": mode.Since},
+	}
+
+	var buf bytes.Buffer
+	err := errors.New("no renderer")
+	if c.Render != nil {
+		// the session is not loaded yet, so the engine is used directly
+		// rather than through Page, which would add the session's data
+		var e render.Engine
+		e, err = c.Render.Engine(c.Config.Renderer)
+		if err == nil {
+			err = e.Render(&buf, r, "errors/503", nil, td)
+		}
+	}
+
+	if err != nil {
+		c.ErrorStatus(w, http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = buf.WriteTo(w)
+}
+
+// maintenanceToken is the bypass cookie value for a secret, so that the
+// secret itself is not stored in the browser
+func maintenanceToken(secret string) string {
+	sum := sha256.Sum256([]byte("celeritas-maintenance:" + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseIPNet accepts a CIDR range or a single IP address
+func parseIPNet(entry string) (*net.IPNet, bool) {
+	entry = strings.TrimSpace(entry)
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return network, true
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, false
+	}
+
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, true
+}
+
+// clientIP returns the request's remote address without the port. The
+// RealIP middleware has already applied X-Forwarded-For and X-Real-IP.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
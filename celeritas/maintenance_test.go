@@ -0,0 +1,104 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCeleritas_Maintenance(t *testing.T) {
+	app, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{
+			Port:        4000,
+			Renderer:    "jet",
+			SessionType: "cookie",
+			Maintenance: MaintenanceConfig{AllowedIPs: []string{"10.0.0.0/8"}},
+		}),
+		WithViews(fstest.MapFS{
+			"errors/503.jet": &fstest.MapFile{Data: []byte(`Back in {{ .Data["retry"] }} seconds`)},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("home"))
+	})
+
+	get := func(target, remoteAddr string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", target, nil)
+		r.RemoteAddr = remoteAddr
+		for _, cookie := range cookies {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		app.Routes.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("/", "192.0.2.1:1234"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 while up, got %d", w.Code)
+	}
+
+	err = app.Down(MaintenanceMode{Secret: "let-me-in", Retry: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := get("/", "192.0.2.1:1234")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while down, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected Retry-After 60, got %q", w.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(w.Body.String(), "Back in 60 seconds") {
+		t.Errorf("503 template was not rendered: %q", w.Body.String())
+	}
+
+	if w := get("/", "10.1.2.3:1234"); w.Code != http.StatusOK {
+		t.Errorf("expected an allow-listed address to get through, got %d", w.Code)
+	}
+
+	w = get("/let-me-in", "192.0.2.1:1234")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected the secret to redirect, got %d", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != maintenanceCookie {
+		t.Fatalf("expected the bypass cookie, got %v", cookies)
+	}
+
+	if w := get("/", "192.0.2.1:1234", cookies[0]); w.Code != http.StatusOK {
+		t.Errorf("expected the bypass cookie to get through, got %d", w.Code)
+	}
+
+	err = app.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := get("/", "192.0.2.1:1234"); w.Code != http.StatusOK {
+		t.Errorf("expected 200 once back up, got %d", w.Code)
+	}
+
+	// another process, e.g. celeritas down, writes the marker; it is only
+	// looked at again once the check interval has passed
+	err = os.WriteFile(maintenanceFile(app.RootPath), []byte(`{"retry":30}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := get("/", "192.0.2.1:1234"); w.Code != http.StatusOK {
+		t.Errorf("expected the marker not to be read on every request, got %d", w.Code)
+	}
+	app.maintenance.checked = time.Now().Add(-maintenanceCheckInterval)
+	if w := get("/", "192.0.2.1:1234"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 once the check interval passed, got %d", w.Code)
+	}
+}
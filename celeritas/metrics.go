@@ -0,0 +1,144 @@
+package celeritas
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/leetrent/celeritas/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// httpMetrics are the collectors updated by RecordMetrics
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+// setupMetrics creates the application's metrics registry and registers
+// the HTTP, database, redis and cache collectors. Applications can add
+// their own collectors to c.Metrics.
+func (c *Celeritas) setupMetrics() error {
+	c.Metrics = prometheus.NewRegistry()
+
+	labels := []string{"method", "route", "status"}
+	c.httpMetrics = &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "celeritas_http_requests_total",
+			Help: "Number of HTTP requests handled.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "celeritas_http_request_duration_seconds",
+			Help:    "Time taken to handle HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "celeritas_http_response_size_bytes",
+			Help:    "Size of HTTP response bodies.",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+		}, labels),
+	}
+
+	cs := []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		c.httpMetrics.requests,
+		c.httpMetrics.duration,
+		c.httpMetrics.size,
+	}
+
+	if c.DB.Pool != nil {
+		cs = append(cs, collectors.NewDBStatsCollector(c.DB.Pool, "writer"))
+	}
+	if c.DB.Reader != nil && c.DB.Reader != c.DB.Pool {
+		cs = append(cs, collectors.NewDBStatsCollector(c.DB.Reader, "reader"))
+	}
+
+	if c.redisPool != nil {
+		pool := c.redisPool
+		cs = append(cs,
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "celeritas_redis_pool_active_connections",
+				Help: "Number of connections in the redis pool, in use or idle.",
+			}, func() float64 { return float64(pool.Stats().ActiveCount) }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "celeritas_redis_pool_idle_connections",
+				Help: "Number of idle connections in the redis pool.",
+			}, func() float64 { return float64(pool.Stats().IdleCount) }),
+		)
+	}
+
+	if reporter, ok := c.Cache.(cache.StatsReporter); ok {
+		cs = append(cs,
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "celeritas_cache_hits_total",
+				Help: "Number of cache lookups which found their key.",
+			}, func() float64 { return float64(reporter.Stats().Hits) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Name: "celeritas_cache_misses_total",
+				Help: "Number of cache lookups which did not find their key.",
+			}, func() float64 { return float64(reporter.Stats().Misses) }),
+		)
+	}
+
+	for _, collector := range cs {
+		err := c.Metrics.Register(collector)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MetricsHandler serves the metrics in c.Metrics in the Prometheus text format
+func (c *Celeritas) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(c.Metrics, promhttp.HandlerOpts{ErrorLog: c.ErrorLog})
+}
+
+// MetricsEndpoint serves the metrics at METRICS_PATH, ahead of the
+// session and CSRF middleware
+func (c *Celeritas) MetricsEndpoint(next http.Handler) http.Handler {
+	metrics := c.MetricsHandler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == c.Config.Metrics.Path {
+			metrics.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RecordMetrics counts requests and records their latency and response
+// size, labelled by method, chi route pattern and status. Requests which
+// match no route share the route label "unmatched", so that arbitrary
+// paths cannot create new series.
+func (c *Celeritas) RecordMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{"method": r.Method, "route": route, "status": strconv.Itoa(status)}
+		c.httpMetrics.requests.With(labels).Inc()
+		c.httpMetrics.duration.With(labels).Observe(time.Since(start).Seconds())
+		c.httpMetrics.size.With(labels).Observe(float64(ww.BytesWritten()))
+	})
+}
@@ -0,0 +1,75 @@
+package celeritas
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/gomodule/redigo/redis"
+	"github.com/leetrent/celeritas/cache"
+)
+
+func TestCeleritas_Metrics(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	redisCache := &cache.RedisCache{
+		Conn: &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", s.Addr()) }},
+	}
+
+	app, err := NewApp(
+		WithConfig(&Config{
+			Port:        4000,
+			Renderer:    "jet",
+			SessionType: "cookie",
+			Metrics:     MetricsConfig{Enabled: true, Path: "/metrics"},
+		}),
+		WithDB(db),
+		WithCache(redisCache),
+		WithViews(fstest.MapFS{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Routes.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = app.Cache.Get("user:" + chi.URLParam(r, "id"))
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	for _, target := range []string{"/users/1", "/users/2", "/nowhere"} {
+		app.Routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+
+	w := httptest.NewRecorder()
+	app.Routes.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`celeritas_http_requests_total{method="GET",route="/users/{id}",status="200"} 2`,
+		`celeritas_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`celeritas_http_response_size_bytes_sum{method="GET",route="/users/{id}",status="200"} 10`,
+		`celeritas_http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`,
+		`go_sql_max_open_connections{db_name="writer"}`,
+		`celeritas_redis_pool_active_connections`,
+		`celeritas_cache_misses_total 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics are missing %s", want)
+		}
+	}
+}
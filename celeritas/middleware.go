@@ -2,7 +2,6 @@ package celeritas
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/justinas/nosurf"
 )
@@ -14,16 +13,15 @@ func (c *Celeritas) SessionLoad(next http.Handler) http.Handler {
 
 func (c *Celeritas) NoSurf(next http.Handler) http.Handler {
 	csrfHandler := nosurf.New(next)
-	secure, _ := strconv.ParseBool(c.config.cookie.secure)
 
 	csrfHandler.ExemptGlob("/api/*")
 
 	csrfHandler.SetBaseCookie(http.Cookie{
 		HttpOnly: true,
 		Path:     "/",
-		Secure:   secure,
+		Secure:   c.Config.Cookie.Secure || c.Config.Secure,
 		SameSite: http.SameSiteStrictMode,
-		Domain:   c.config.cookie.domain,
+		Domain:   c.Config.Cookie.Domain,
 	})
 
 	return csrfHandler
@@ -1,81 +1,93 @@
 package celeritas
 
 import (
-	"log"
+	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 )
 
+// migrator reads the migrations from c.Migrations, which may be embedded
+// in the binary, or from the migrations folder when it is not set
+func (c *Celeritas) migrator(dsn string) (*migrate.Migrate, error) {
+	src, err := iofs.New(c.migrationsFS(), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.NewWithSourceInstance("iofs", src, dsn)
+}
+
+func (c *Celeritas) migrationsFS() fs.FS {
+	if c.Migrations != nil {
+		return c.Migrations
+	}
+	return os.DirFS(filepath.Join(c.RootPath, "migrations"))
+}
+
 func (c *Celeritas) MigrateUp(dsn string) error {
-	rootPath := filepath.ToSlash(c.RootPath)
-	m, err := migrate.New("file://"+rootPath+"/migrations", dsn)
+	m, err := c.migrator(dsn)
 	if err != nil {
 		return err
 	}
 	defer m.Close()
 
 	if err := m.Up(); err != nil {
-		log.Println("[migrate.Migrate.Up] => (error): ", err)
-		return err
+		return fmt.Errorf("migrate up: %w", err)
 	}
 
 	return nil
 }
 
 func (c *Celeritas) MigrateDownAll(dsn string) error {
-	rootPath := filepath.ToSlash(c.RootPath)
-	m, err := migrate.New("file://"+rootPath+"/migrations", dsn)
+	m, err := c.migrator(dsn)
 	if err != nil {
 		return err
 	}
 	defer m.Close()
 
 	if err = m.Down(); err != nil {
-		log.Println("[migrate.Migrate.Down] => (error): ", err)
-		return err
+		return fmt.Errorf("migrate down: %w", err)
 	}
 
 	return nil
 }
 
 func (c *Celeritas) Steps(n int, dsn string) error {
-	rootPath := filepath.ToSlash(c.RootPath)
-	m, err := migrate.New("file://"+rootPath+"/migrations", dsn)
+	m, err := c.migrator(dsn)
 	if err != nil {
 		return err
 	}
 	defer m.Close()
 
 	if err := m.Steps(n); err != nil {
-		log.Println("[migrate.Migrate.Steps] => (error): ", err)
-		return err
+		return fmt.Errorf("migrate steps: %w", err)
 	}
 
 	return nil
 }
 
 func (c *Celeritas) MigrateForce(dsn string) error {
-	rootPath := filepath.ToSlash(c.RootPath)
-	m, err := migrate.New("file://"+rootPath+"/migrations", dsn)
+	m, err := c.migrator(dsn)
 	if err != nil {
 		return err
 	}
 	defer m.Close()
 
 	if err := m.Force(-1); err != nil {
-		log.Println("[migrate.MigrateForce] => (error): ", err)
-		return err
+		return fmt.Errorf("migrate force: %w", err)
 	}
 
 	if err := m.Force(-1); err != nil {
-		log.Println("[migrate.Migrate.Force] => (error): ", err)
-		return err
+		return fmt.Errorf("migrate force: %w", err)
 	}
 
 	return nil
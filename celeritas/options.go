@@ -0,0 +1,168 @@
+package celeritas
+
+import (
+	"database/sql"
+	"io/fs"
+	"reflect"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/leetrent/celeritas/cache"
+)
+
+// options collects everything NewApp can be given instead of creating it
+type options struct {
+	rootPath   string
+	config     *Config
+	db         *sql.DB
+	cache      cache.Cache
+	session    *scs.SessionManager
+	views      fs.FS
+	public     fs.FS
+	migrations fs.FS
+}
+
+// Option configures an application built by NewApp
+type Option func(*options)
+
+// WithRootPath sets the application root, used to locate the views,
+// migrations and tmp folders when they are not supplied otherwise
+func WithRootPath(path string) Option {
+	return func(o *options) {
+		o.rootPath = path
+	}
+}
+
+// WithConfig uses cfg instead of reading the configuration from the
+// environment
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithDB uses an already opened database pool instead of connecting to
+// the configured database
+func WithDB(db *sql.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithCache uses the given cache instead of connecting to the configured one
+func WithCache(c cache.Cache) Option {
+	return func(o *options) {
+		o.cache = c
+	}
+}
+
+// WithSession uses the given session manager instead of creating one
+// from the cookie and session store configuration
+func WithSession(sm *scs.SessionManager) Option {
+	return func(o *options) {
+		o.session = sm
+	}
+}
+
+// WithViews loads templates from fsys (e.g. an embed.FS) instead of the
+// views folder under the application root
+func WithViews(fsys fs.FS) Option {
+	return func(o *options) {
+		o.views = fsys
+	}
+}
+
+// WithPublic serves static files from fsys instead of the public folder
+// under the application root
+func WithPublic(fsys fs.FS) Option {
+	return func(o *options) {
+		o.public = fsys
+	}
+}
+
+// WithMigrations runs migrations from fsys instead of the migrations
+// folder under the application root
+func WithMigrations(fsys fs.FS) Option {
+	return func(o *options) {
+		o.migrations = fsys
+	}
+}
+
+// WithFS takes the views, public and migrations folders from fsys, so that
+// an application can embed them and ship as a single binary:
+//
+//	//go:embed views public migrations
+//	var files embed.FS
+//
+//	err = cel.New(path, celeritas.WithFS(files))
+//
+// In debug mode the folders under the application root are used instead,
+// when they exist, so that edits show up without rebuilding.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.views = subFS(fsys, "views")
+		o.public = subFS(fsys, "public")
+		o.migrations = subFS(fsys, "migrations")
+	}
+}
+
+// subFS returns the dir folder of fsys, or nil when fsys has no such folder
+func subFS(fsys fs.FS, dir string) fs.FS {
+	info, err := fs.Stat(fsys, dir)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil
+	}
+	return sub
+}
+
+// NewApp builds a celeritas application from explicit options. Unlike
+// New, it never creates folders or files, and it reports failures as
+// errors rather than exiting. All state lives on the returned instance,
+// so several applications can run in the same process.
+//
+// Without WithConfig, the configuration is read from the environment
+// (plus .env files under the root path, if one was given). A Config given
+// with WithConfig has its unset fields filled with the same defaults, and
+// is validated the same way; booleans are taken as they are.
+func NewApp(opts ...Option) (*Celeritas, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.config != nil {
+		cfg := *o.config
+		setDefaults(reflect.ValueOf(&cfg).Elem())
+
+		cfgErr := &ConfigError{}
+		cfg.validate(cfgErr)
+		if !cfgErr.empty() {
+			return nil, cfgErr
+		}
+		o.config = &cfg
+	} else {
+		var cfg *Config
+		var err error
+		if o.rootPath != "" {
+			cfg, err = LoadConfig(o.rootPath)
+		} else {
+			cfg, err = ConfigFromEnv()
+		}
+		if err != nil {
+			return nil, err
+		}
+		o.config = cfg
+	}
+
+	c := &Celeritas{}
+	err := c.setup(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,182 @@
+package celeritas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+func TestNewApp(t *testing.T) {
+	root := t.TempDir()
+	views := fstest.MapFS{
+		"home.jet": &fstest.MapFile{Data: []byte("Hello from {{ .ServerName }}")},
+	}
+
+	sm := scs.New()
+	first, err := NewApp(
+		WithRootPath(root),
+		WithConfig(&Config{AppName: "first", Port: 4000, ServerName: "first.test", Renderer: "jet", SessionType: "cookie"}),
+		WithSession(sm),
+		WithViews(views),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := NewApp(
+		WithConfig(&Config{AppName: "second", Port: 4001, Renderer: "jet", SessionType: "cookie"}),
+		WithViews(views),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Session != sm {
+		t.Error("the given session manager was not used")
+	}
+	if first.Session == second.Session || first.Routes == second.Routes {
+		t.Error("applications share state")
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("NewApp created %d entries in the root path", len(entries))
+	}
+
+	first.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		err := first.Render.Page(w, r, "home", nil, nil)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	first.Routes.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "Hello from first.test" {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+}
+
+func TestNewApp_DatabaseUnavailable(t *testing.T) {
+	cfg := &Config{
+		Port:        4000,
+		Renderer:    "jet",
+		SessionType: "cookie",
+		Database: DatabaseConfig{
+			Type:    "postgres",
+			Host:    "127.0.0.1",
+			Port:    1,
+			User:    "nobody",
+			Name:    "nothing",
+			SSLMode: "disable",
+
+			ConnectTimeout: 100 * time.Millisecond,
+		},
+	}
+
+	_, err := NewApp(WithConfig(cfg))
+	if err == nil {
+		t.Error("expected an error when the database is unreachable")
+	}
+}
+
+func TestNewApp_ConfigDefaults(t *testing.T) {
+	given := &Config{}
+	app, err := NewApp(WithConfig(given), WithViews(fstest.MapFS{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Config.Port != 4000 || app.Config.Renderer != "jet" || app.Config.SessionType != "cookie" {
+		t.Errorf("defaults not applied: port %d, renderer %q, sessions %q", app.Config.Port, app.Config.Renderer, app.Config.SessionType)
+	}
+	if app.Config.Cookie.Lifetime != 1440 || app.Config.Security.CSP == "" {
+		t.Errorf("nested defaults not applied: lifetime %d, csp %q", app.Config.Cookie.Lifetime, app.Config.Security.CSP)
+	}
+	if given.Port != 0 {
+		t.Error("the given config was changed")
+	}
+
+	_, err = NewApp(WithConfig(&Config{Port: 70000, SessionType: "postgres"}), WithViews(fstest.MapFS{}))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Invalid) != 2 {
+		t.Errorf("expected a ConfigError for the port and session store, got %v", err)
+	}
+}
+
+func TestNewApp_WithFS(t *testing.T) {
+	files := fstest.MapFS{
+		"views/home.jet":                &fstest.MapFile{Data: []byte("embedded")},
+		"public/app.css":                &fstest.MapFile{Data: []byte("body{}")},
+		"migrations/1_widgets.up.sql":   &fstest.MapFile{Data: []byte("create table widgets (id integer primary key);")},
+		"migrations/1_widgets.down.sql": &fstest.MapFile{Data: []byte("drop table widgets;")},
+		"migrations/2_gadgets.up.sql":   &fstest.MapFile{Data: []byte("create table gadgets (id integer primary key);")},
+		"migrations/2_gadgets.down.sql": &fstest.MapFile{Data: []byte("drop table gadgets;")},
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "views"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "views", "home.jet"), []byte("on disk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dbFile := filepath.Join(root, "app.db")
+	cfg := &Config{Port: 4000, Renderer: "jet", SessionType: "cookie", Database: DatabaseConfig{Type: "sqlite", Name: dbFile, SSLMode: "disable"}}
+	app, err := NewApp(WithRootPath(root), WithConfig(cfg), WithFS(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.DB.Pool.Close()
+
+	debugCfg := *cfg
+	debugCfg.Debug = true
+	debug, err := NewApp(WithRootPath(root), WithConfig(&debugCfg), WithFS(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer debug.DB.Pool.Close()
+
+	for _, e := range []struct {
+		app      *Celeritas
+		expected string
+	}{{app, "embedded"}, {debug, "on disk"}} {
+		w := httptest.NewRecorder()
+		ctx, _ := e.app.Session.Load(context.Background(), "")
+		err = e.app.Render.Page(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx), "home", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.Body.String() != e.expected {
+			t.Errorf("expected %q, got %q", e.expected, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	http.StripPrefix("/public", app.FileServer(http.FS(app.Public))).ServeHTTP(w, httptest.NewRequest("GET", "/public/app.css", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "body{}" || w.Header().Get("ETag") == "" {
+		t.Errorf("embedded file not served: %d %q", w.Code, w.Body.String())
+	}
+
+	err = app.MigrateUp("sqlite://" + dbFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"widgets", "gadgets"} {
+		if _, err := app.DB.Pool.Exec("select count(*) from " + table); err != nil {
+			t.Errorf("embedded migration did not create %s: %s", table, err)
+		}
+	}
+}
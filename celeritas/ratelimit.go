@@ -0,0 +1,223 @@
+package celeritas
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leetrent/celeritas/cache"
+)
+
+// rate limiting strategies
+const (
+	// FixedWindow counts requests in consecutive windows, e.g. each minute
+	FixedWindow = "fixed"
+
+	// SlidingWindow weights the previous window's count by how much of it
+	// still overlaps the last Window, which smooths out bursts at window
+	// boundaries
+	SlidingWindow = "sliding"
+)
+
+// the limit used when a RateLimit leaves Requests or Window unset
+const (
+	DefaultRateLimitRequests = 60
+	DefaultRateLimitWindow   = time.Minute
+)
+
+// RateLimit describes how many requests a client may make
+type RateLimit struct {
+	// Name keeps the counters of different limits apart, e.g. "login"
+	Name string
+
+	// Requests is the number of requests allowed per Window. Either one,
+	// when zero or negative, is replaced by DefaultRateLimitRequests or
+	// DefaultRateLimitWindow.
+	Requests int
+	Window   time.Duration
+
+	// Strategy is FixedWindow (the default) or SlidingWindow
+	Strategy string
+
+	// Key identifies the client; it defaults to RateLimitByIP
+	Key func(r *http.Request) string
+}
+
+// RateLimitByIP identifies clients by their IP address
+func RateLimitByIP(r *http.Request) string {
+	return "ip:" + clientIP(r)
+}
+
+// RateLimitByUser identifies clients by the logged in user's id, and
+// anonymous clients by their IP address
+func (c *Celeritas) RateLimitByUser(r *http.Request) string {
+	if c.Session != nil && c.Session.Exists(r.Context(), "userID") {
+		return fmt.Sprintf("user:%v", c.Session.Get(r.Context(), "userID"))
+	}
+	return RateLimitByIP(r)
+}
+
+// RateLimitByToken identifies clients by their bearer token, and clients
+// without one by their IP address. Tokens are hashed before being used
+// as cache keys.
+func RateLimitByToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if token := strings.TrimPrefix(header, "Bearer "); token != header && token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+	return RateLimitByIP(r)
+}
+
+// RateLimiter returns middleware which answers 429 Too Many Requests once
+// a client exceeds the limit. Counters are kept in the application cache
+// when it supports them (redis and badger do), so that limits are shared
+// between instances, and in memory otherwise. Every response carries
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+//
+//	r.Use(app.RateLimiter(celeritas.RateLimit{Name: "api", Requests: 60, Window: time.Minute}))
+func (c *Celeritas) RateLimiter(limit RateLimit) func(http.Handler) http.Handler {
+	key := limit.Key
+	if key == nil {
+		key = RateLimitByIP
+	}
+	// a zero window would truncate every time to itself and store
+	// counters which expire at once, or never, depending on the cache
+	if limit.Requests <= 0 {
+		limit.Requests = DefaultRateLimitRequests
+	}
+	if limit.Window <= 0 {
+		limit.Window = DefaultRateLimitWindow
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			windowStart := now.Truncate(limit.Window)
+			reset := windowStart.Add(limit.Window)
+			prefix := fmt.Sprintf("ratelimit:%s:%s:", limit.Name, key(r))
+
+			count, err := c.rateLimitCount(limit, prefix, now, windowStart)
+			if err != nil {
+				// fail open, rather than lock everyone out when the cache is down
+				c.RequestLogger(r).Error("rate limit", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			remaining := limit.Requests - count
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+			if count > limit.Requests {
+				retry := int(math.Ceil(reset.Sub(now).Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(retry))
+				c.ErrorStatus(w, http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitCount counts the current request and returns the number of
+// requests the client has made in the window, including this one
+func (c *Celeritas) rateLimitCount(limit RateLimit, prefix string, now, windowStart time.Time) (int, error) {
+	counter := c.rateLimitCounter()
+	current := prefix + strconv.FormatInt(windowStart.UnixNano(), 10)
+
+	if limit.Strategy != SlidingWindow {
+		count, err := counter.Increment(current, limit.Window)
+		return int(count), err
+	}
+
+	// the current window's counter is also read as the previous window
+	// during the next one, so it has to outlive it
+	count, err := counter.Increment(current, 2*limit.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	previous := prefix + strconv.FormatInt(windowStart.Add(-limit.Window).UnixNano(), 10)
+	prevCount, err := counter.Count(previous)
+	if err != nil {
+		return 0, err
+	}
+
+	overlap := 1 - float64(now.Sub(windowStart))/float64(limit.Window)
+	return int(float64(prevCount)*overlap) + int(count), nil
+}
+
+// rateLimitCounter returns the application cache when it can keep
+// counters, or an in-memory counter shared by this application's limits
+func (c *Celeritas) rateLimitCounter() cache.Counter {
+	if counter, ok := c.Cache.(cache.Counter); ok {
+		return counter
+	}
+
+	c.rateLimitOnce.Do(func() {
+		c.memoryCounter = &memoryCounter{counts: make(map[string]memoryCount)}
+	})
+	return c.memoryCounter
+}
+
+type memoryCount struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// memoryCounter is the cache.Counter used when the application cache
+// cannot keep counters. Expired counters are dropped as they are touched
+// and every so often all at once.
+type memoryCounter struct {
+	mu     sync.Mutex
+	counts map[string]memoryCount
+	sweeps int
+}
+
+func (m *memoryCounter) Increment(key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweeps++
+	if m.sweeps%1000 == 0 {
+		for k, v := range m.counts {
+			if now.After(v.expiresAt) {
+				delete(m.counts, k)
+			}
+		}
+	}
+
+	entry, ok := m.counts[key]
+	if !ok || now.After(entry.expiresAt) {
+		entry = memoryCount{expiresAt: now.Add(ttl)}
+	}
+	entry.count++
+	m.counts[key] = entry
+
+	return entry.count, nil
+}
+
+func (m *memoryCounter) Count(key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.counts[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, nil
+	}
+	return entry.count, nil
+}
@@ -0,0 +1,85 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCeleritas_RateLimiter(t *testing.T) {
+	c := &Celeritas{}
+	handler := c.RateLimiter(RateLimit{Name: "login", Requests: 3, Window: time.Hour})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/users/login", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 1; i <= 3; i++ {
+		w := request("192.0.2.1:1234")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, w.Code)
+		}
+		if w.Header().Get("X-RateLimit-Remaining") != strconv.Itoa(3-i) {
+			t.Errorf("request %d: unexpected X-RateLimit-Remaining %q", i, w.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+
+	w := request("192.0.2.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 once over the limit, got %d", w.Code)
+	}
+	if retry, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retry <= 0 || retry > 3600 {
+		t.Errorf("unexpected Retry-After %q", w.Header().Get("Retry-After"))
+	}
+
+	if w := request("192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("expected another client to have its own limit, got %d", w.Code)
+	}
+}
+
+func TestCeleritas_RateLimiterDefaults(t *testing.T) {
+	c := &Celeritas{}
+	handler := c.RateLimiter(RateLimit{Name: "unset"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != strconv.Itoa(DefaultRateLimitRequests) {
+		t.Errorf("expected the default limit, got %d with limit %q", w.Code, w.Header().Get("X-RateLimit-Limit"))
+	}
+
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if until := time.Until(time.Unix(reset, 0)); err != nil || until <= 0 || until > DefaultRateLimitWindow {
+		t.Errorf("expected the window to reset within the default window, got %q", w.Header().Get("X-RateLimit-Reset"))
+	}
+}
+
+func TestCeleritas_RateLimitSlidingWindow(t *testing.T) {
+	c := &Celeritas{}
+	limit := RateLimit{Requests: 100, Window: time.Minute, Strategy: SlidingWindow}
+
+	previousStart := time.Now().Truncate(time.Minute).Add(-time.Minute)
+	for i := 0; i < 10; i++ {
+		_, err := c.rateLimitCount(limit, "test:", previousStart, previousStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a quarter of the way into the next window, three quarters of the
+	// previous window's requests still count
+	windowStart := previousStart.Add(time.Minute)
+	count, err := c.rateLimitCount(limit, "test:", windowStart.Add(15*time.Second), windowStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 8 {
+		t.Errorf("expected 7 weighted requests plus this one, got %d", count)
+	}
+}
@@ -0,0 +1,61 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/leetrent/celeritas/render"
+)
+
+// RedirectBackWithErrors sends the browser back to the form it posted,
+// keeping the validation errors and the values submitted in the session so
+// that the form can show them, through error("email") and old("email") in
+// templates. Refreshing or going back then repeats the GET, not the POST.
+//
+//	if !validator.Valid() {
+//		h.App.RedirectBackWithErrors(w, r, validator)
+//		return
+//	}
+//
+// The values are validation.Data, or else the posted form. The CSRF token
+// and anything which looks like a password are left out.
+func (c *Celeritas) RedirectBackWithErrors(w http.ResponseWriter, r *http.Request, validation *Validation) {
+	input := validation.Data
+	if input == nil {
+		_ = r.ParseForm()
+		input = r.PostForm
+	}
+
+	old := url.Values{}
+	for field, values := range input {
+		if keepInput(field) {
+			old[field] = values
+		}
+	}
+
+	render.PutFormInput(c.Session, r.Context(), validation.Errors, old)
+	http.Redirect(w, r, backURL(r), http.StatusSeeOther)
+}
+
+// keepInput reports whether a form field may be stored in the session and
+// sent back to the browser
+func keepInput(field string) bool {
+	switch field {
+	case "csrf_token", "_method":
+		return false
+	}
+	return !strings.Contains(strings.ToLower(field), "password")
+}
+
+// backURL is the page the request came from, according to its Referer, or
+// else its own path. A Referer on another host is ignored, so that the
+// redirect cannot leave the site.
+func backURL(r *http.Request) string {
+	if referer, err := url.Parse(r.Referer()); err == nil && referer.Path != "" {
+		if referer.Host == "" || referer.Host == r.Host {
+			return referer.RequestURI()
+		}
+	}
+	return r.URL.Path
+}
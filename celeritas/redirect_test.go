@@ -0,0 +1,93 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCeleritas_RedirectBackWithErrors(t *testing.T) {
+	app, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{Port: 4000, Renderer: "go", SessionType: "cookie", Cookie: CookieConfig{Name: "celeritas", Lifetime: 60}}),
+		WithViews(fstest.MapFS{
+			"form.page.tmpl": &fstest.MapFile{Data: []byte(
+				`{{ if hasError }}!{{ end }}[{{ old "email" }}][{{ error "email" }}][{{ hasError "name" }}][{{ old "password" }}][{{ old "name" "default" }}]`)},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := chi.NewRouter()
+	mux.Use(app.SessionLoad)
+	mux.Get("/form", func(w http.ResponseWriter, r *http.Request) {
+		if err := app.Render.Page(w, r, "form", nil, nil); err != nil {
+			t.Error(err)
+		}
+	})
+	mux.Post("/form", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		validator := app.Validator(nil)
+		validator.IsEmail("email", r.Form.Get("email"))
+		app.RedirectBackWithErrors(w, r, validator)
+	})
+
+	form := url.Values{"email": {"not-an-email"}, "password": {"secret"}}
+	r := httptest.NewRequest("POST", "/form", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Referer", "http://example.com/form?step=2")
+	r.Host = "example.com"
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/form?step=2" {
+		t.Fatalf("expected a redirect back to /form?step=2, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	get := func() string {
+		r := httptest.NewRequest("GET", "/form", nil)
+		for _, cookie := range w.Result().Cookies() {
+			r.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		return rec.Body.String()
+	}
+
+	expected := "![not-an-email][Invalid email address][false][][default]"
+	if got := get(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := get(); got != "[][][false][][default]" {
+		t.Errorf("errors and old input were shown twice: %q", got)
+	}
+}
+
+func TestBackURL(t *testing.T) {
+	var tests = []struct {
+		name     string
+		referer  string
+		expected string
+	}{
+		{"same_host", "http://example.com/users/7/edit", "/users/7/edit"},
+		{"relative", "/users/create?x=1", "/users/create?x=1"},
+		{"other_host", "https://evil.test/phish", "/users"},
+		{"none", "", "/users"},
+	}
+
+	for _, e := range tests {
+		r := httptest.NewRequest("POST", "http://example.com/users", nil)
+		if e.referer != "" {
+			r.Header.Set("Referer", e.referer)
+		}
+		if got := backURL(r); got != e.expected {
+			t.Errorf("%s: expected %q, got %q", e.name, e.expected, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Engine is a template engine. The go and jet engines are built in; others
+// are added with Register, and chosen with RENDERER or by the extension of
+// a view (see Render.Page).
+type Engine interface {
+	// Load prepares the engine to read its templates from views
+	Load(views fs.FS) error
+	// Render executes the template for view, e.g. "home" or
+	// "errors/503", writing the result to w
+	Render(w io.Writer, r *http.Request, view string, variables interface{}, data *TemplateData) error
+	// AddFuncs makes functions available to every template
+	AddFuncs(funcs map[string]interface{})
+	// Reload discards any parsed templates, so that changes are read
+	Reload() error
+	// Extension is the file extension of the engine's templates,
+	// e.g. ".jet"
+	Extension() string
+}
+
+// EngineFactory creates an engine. With useCache the engine may keep
+// parsed templates; without it, in debug mode, templates should be read
+// again on every request.
+type EngineFactory func(useCache bool) Engine
+
+var (
+	enginesMu sync.RWMutex
+	factories = make(map[string]EngineFactory)
+)
+
+// Register makes a template engine available by name. It is meant to be
+// called from the init function of the package providing the engine, and
+// panics if the name is already taken.
+//
+//	func init() {
+//		render.Register("pongo", func(useCache bool) render.Engine {
+//			return &PongoEngine{cache: useCache}
+//		})
+//	}
+func Register(name string, factory EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+
+	if factory == nil {
+		panic("render: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("render: Register called twice for engine " + name)
+	}
+	factories[name] = factory
+}
+
+// Engines returns the names of the registered engines, sorted
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("go", func(useCache bool) Engine {
+		return &GoEngine{UseCache: useCache}
+	})
+	Register("jet", func(useCache bool) Engine {
+		return &JetEngine{UseCache: useCache}
+	})
+}
+
+// Load creates and loads every registered engine, giving each the Funcs.
+// Page calls it when it has not been called, but calling it at startup
+// finds broken templates before any request does.
+func (c *Render) Load() error {
+	c.enginesMu.Lock()
+	defer c.enginesMu.Unlock()
+
+	return c.load()
+}
+
+func (c *Render) load() error {
+	if c.engines != nil {
+		return nil
+	}
+
+	engines := make(map[string]Engine)
+	for _, name := range Engines() {
+		enginesMu.RLock()
+		factory := factories[name]
+		enginesMu.RUnlock()
+
+		var e Engine
+		if name == "jet" && c.JetViews != nil {
+			// a jet.Set made by the application, which is already loaded
+			e = &JetEngine{Set: c.JetViews}
+			e.AddFuncs(c.Funcs)
+		} else {
+			e = factory(c.UseCache)
+			e.AddFuncs(c.Funcs)
+			if err := e.Load(c.viewsFS()); err != nil {
+				return fmt.Errorf("loading %s templates: %w", name, err)
+			}
+		}
+		engines[name] = e
+	}
+
+	c.engines = engines
+	return nil
+}
+
+// Engine returns the named engine
+func (c *Render) Engine(name string) (Engine, error) {
+	c.enginesMu.Lock()
+	defer c.enginesMu.Unlock()
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	e, ok := c.engines[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rendering engine %q", name)
+	}
+	return e, nil
+}
+
+// Reload makes every engine read its templates again
+func (c *Render) Reload() error {
+	c.enginesMu.Lock()
+	defer c.enginesMu.Unlock()
+
+	if err := c.load(); err != nil {
+		return err
+	}
+	for name, e := range c.engines {
+		if err := e.Reload(); err != nil {
+			return fmt.Errorf("reloading %s templates: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package render
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+// plainEngine writes its templates out as they are, followed by the value
+// of its one function
+type plainEngine struct {
+	views fs.FS
+	funcs map[string]interface{}
+}
+
+func (e *plainEngine) Load(views fs.FS) error { e.views = views; return nil }
+func (e *plainEngine) Reload() error          { return nil }
+func (e *plainEngine) Extension() string      { return ".txt" }
+
+func (e *plainEngine) AddFuncs(funcs map[string]interface{}) { e.funcs = funcs }
+
+func (e *plainEngine) Render(w io.Writer, r *http.Request, view string, variables interface{}, data *TemplateData) error {
+	content, err := fs.ReadFile(e.views, view+e.Extension())
+	if err != nil {
+		return err
+	}
+	greet := e.funcs["greet"].(func() string)
+	_, err = w.Write(append(content, greet()...))
+	return err
+}
+
+func init() {
+	Register("plain", func(useCache bool) Engine { return &plainEngine{} })
+}
+
+func TestRender_Engines(t *testing.T) {
+	fsys := fstest.MapFS{
+		"home.txt":       &fstest.MapFile{Data: []byte("plain ")},
+		"home.jet":       &fstest.MapFile{Data: []byte("jet")},
+		"home.page.tmpl": &fstest.MapFile{Data: []byte("go")},
+		"only-jet.jet":   &fstest.MapFile{Data: []byte("jet only")},
+	}
+
+	var tests = []struct {
+		name     string
+		renderer string
+		view     string
+		expected string
+	}{
+		{"configured", "plain", "home", "plain hello"},
+		{"configured_go", "go", "home", "go"},
+		{"by_extension", "go", "home.jet", "jet"},
+		{"by_go_extension", "jet", "home.page.tmpl", "go"},
+		{"fallback_to_other_engine", "go", "only-jet", "jet only"},
+		{"no_configured_engine", "", "only-jet", "jet only"},
+	}
+
+	for _, e := range tests {
+		rend := Render{
+			Renderer: e.renderer,
+			Views:    fsys,
+			Session:  testSession,
+			Funcs:    map[string]interface{}{"greet": func() string { return "hello" }},
+		}
+
+		r, err := getRequest("GET", "/some-url")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+
+		err = rend.Page(w, r, e.view, nil, nil)
+		if err != nil {
+			t.Errorf("%s: %s", e.name, err)
+			continue
+		}
+		if got := w.Body.String(); got != e.expected {
+			t.Errorf("%s: expected %q, got %q", e.name, e.expected, got)
+		}
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering an engine name twice did not panic")
+		}
+	}()
+	Register("go", func(useCache bool) Engine { return &GoEngine{} })
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"context"
+	"encoding/gob"
+	"strings"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// flashKey is the session key flash messages are kept under until they
+// are shown
+const flashKey = "celeritas_flash"
+
+// The flash levels with a field of their own on TemplateData. Any other
+// level, such as "info", is only in TemplateData.Flashes.
+const (
+	LevelFlash   = "flash"
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// FlashMessage is a message kept in the session until the next page is
+// rendered
+type FlashMessage struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// session stores encode their values with gob
+	gob.Register([]FlashMessage{})
+}
+
+// AddFlash adds a message, at a level such as LevelError, to those waiting
+// in the session
+func AddFlash(session *scs.SessionManager, ctx context.Context, level, message string) {
+	flashes, _ := session.Get(ctx, flashKey).([]FlashMessage)
+	session.Put(ctx, flashKey, append(flashes, FlashMessage{Level: level, Message: message}))
+}
+
+// PopFlashes returns the messages waiting in the session, in the order
+// they were added, and removes them
+func PopFlashes(session *scs.SessionManager, ctx context.Context) []FlashMessage {
+	flashes, _ := session.Pop(ctx, flashKey).([]FlashMessage)
+	return flashes
+}
+
+// addFlashes sets Flashes, and the Flash, Error and Warning fields to the
+// messages of their level, separated by spaces
+func addFlashes(td *TemplateData, flashes []FlashMessage) {
+	byLevel := make(map[string][]string)
+	for _, flash := range flashes {
+		byLevel[flash.Level] = append(byLevel[flash.Level], flash.Message)
+	}
+
+	td.Flashes = append(td.Flashes, flashes...)
+	td.Flash = strings.Join(byLevel[LevelFlash], " ")
+	td.Error = strings.Join(byLevel[LevelError], " ")
+	td.Warning = strings.Join(byLevel[LevelWarning], " ")
+}
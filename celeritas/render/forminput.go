@@ -0,0 +1,78 @@
+package render
+
+import (
+	"context"
+	"encoding/gob"
+	"net/url"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// the session keys a failed form submission is kept under until the form
+// is shown again
+const (
+	errorsKey   = "celeritas_errors"
+	oldInputKey = "celeritas_old_input"
+)
+
+func init() {
+	gob.Register(map[string]string{})
+	gob.Register(url.Values{})
+}
+
+// PutFormInput keeps a form's validation errors, by field, and the values
+// submitted, in the session for the next page rendered, where templates
+// read them with error("email") and old("email")
+func PutFormInput(session *scs.SessionManager, ctx context.Context, errors map[string]string, input url.Values) {
+	session.Put(ctx, errorsKey, errors)
+	session.Put(ctx, oldInputKey, input)
+}
+
+// addFormInput moves the errors and input kept by PutFormInput into td,
+// unless the handler has set its own
+func addFormInput(td *TemplateData, session *scs.SessionManager, ctx context.Context) {
+	errors, _ := session.Pop(ctx, errorsKey).(map[string]string)
+	input, _ := session.Pop(ctx, oldInputKey).(url.Values)
+
+	if td.Errors == nil {
+		td.Errors = errors
+	}
+	if td.OldInput == nil {
+		td.OldInput = input
+	}
+}
+
+// old is old("email") in templates: the value submitted for a field, or
+// else the default given, as in old("email", user.Email) on an edit form
+func (td *TemplateData) old(field string, defaults ...string) string {
+	if td != nil {
+		if values, ok := td.OldInput[field]; ok && len(values) > 0 {
+			return values[0]
+		}
+	}
+	if len(defaults) > 0 {
+		return defaults[0]
+	}
+	return ""
+}
+
+// fieldError is error("email") in templates: the field's validation error
+func (td *TemplateData) fieldError(field string) string {
+	if td == nil {
+		return ""
+	}
+	return td.Errors[field]
+}
+
+// hasError is hasError("email") in templates, or hasError() for whether
+// there are any validation errors
+func (td *TemplateData) hasError(field ...string) bool {
+	if td == nil {
+		return false
+	}
+	if len(field) == 0 {
+		return len(td.Errors) > 0
+	}
+	_, ok := td.Errors[field[0]]
+	return ok
+}
@@ -0,0 +1,185 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Go templates are found anywhere under the views folder by their suffix.
+// Each page is parsed together with every layout and partial, so a page
+// can {{ template "base" . }} a layout which defines the blocks it fills.
+const (
+	pageSuffix    = ".page.tmpl"
+	layoutSuffix  = ".layout.tmpl"
+	partialSuffix = ".partial.tmpl"
+)
+
+// GoEngine renders html/template pages, views/<name>.page.tmpl. Variables,
+// a jet.VarMap or a map[string]interface{}, are added to .Data.
+type GoEngine struct {
+	// UseCache parses the templates once, rather than on every request
+	UseCache bool
+
+	funcs template.FuncMap
+	views fs.FS
+
+	cacheMu       sync.RWMutex
+	templateCache map[string]*template.Template
+}
+
+var _ Engine = (*GoEngine)(nil)
+
+// Load sets the views and, with UseCache, parses every page, so that a
+// broken template is found at startup rather than by a request
+func (e *GoEngine) Load(views fs.FS) error {
+	e.views = views
+	if !e.UseCache {
+		return nil
+	}
+	return e.buildCache()
+}
+
+// Reload parses the templates again
+func (e *GoEngine) Reload() error {
+	e.cacheMu.Lock()
+	e.templateCache = nil
+	e.cacheMu.Unlock()
+
+	return e.Load(e.views)
+}
+
+// AddFuncs adds functions to every template. Call it before Load.
+func (e *GoEngine) AddFuncs(funcs map[string]interface{}) {
+	if e.funcs == nil {
+		e.funcs = make(template.FuncMap)
+	}
+	for name, fn := range funcs {
+		e.funcs[name] = fn
+	}
+}
+
+// Extension is .page.tmpl: layouts and partials are not views
+func (e *GoEngine) Extension() string {
+	return pageSuffix
+}
+
+// Render executes the page for view
+func (e *GoEngine) Render(w io.Writer, r *http.Request, view string, variables interface{}, data *TemplateData) error {
+	tmpl, err := e.Template(r, view, data)
+	if err != nil {
+		return err
+	}
+
+	addVariables(data, variables)
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// Template returns the template for a page, e.g. "home" or "errors/503",
+// ready to execute for the request and its data. With UseCache it comes
+// from the cache; otherwise it is parsed afresh so that changes show up
+// straight away.
+func (e *GoEngine) Template(r *http.Request, view string, data *TemplateData) (*template.Template, error) {
+	var tmpl *template.Template
+
+	if e.UseCache {
+		e.cacheMu.RLock()
+		tmpl = e.templateCache[view]
+		e.cacheMu.RUnlock()
+
+		if tmpl == nil {
+			return nil, fmt.Errorf("template %s%s not found", view, pageSuffix)
+		}
+	} else {
+		_, shared, err := findTemplates(e.views)
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err = e.parsePage(view+pageSuffix, shared)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the cached template is never executed, only clones of it, each with
+	// the functions bound to its own request
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Funcs(requestFuncs(r, data)), nil
+}
+
+// buildCache parses every page, with the layouts and partials, and
+// replaces the template cache
+func (e *GoEngine) buildCache() error {
+	pages, shared, err := findTemplates(e.views)
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[string]*template.Template)
+	for _, page := range pages {
+		tmpl, err := e.parsePage(page, shared)
+		if err != nil {
+			return err
+		}
+		cache[strings.TrimSuffix(page, pageSuffix)] = tmpl
+	}
+
+	e.cacheMu.Lock()
+	e.templateCache = cache
+	e.cacheMu.Unlock()
+
+	return nil
+}
+
+// parsePage parses a page with the layouts and partials. The request
+// functions are placeholders here, replaced for each request.
+func (e *GoEngine) parsePage(page string, shared []string) (*template.Template, error) {
+	files := append([]string{page}, shared...)
+	return template.New(path.Base(page)).
+		Funcs(e.funcs).
+		Funcs(requestFuncs(nil, nil)).
+		ParseFS(e.views, files...)
+}
+
+// findTemplates lists the pages, and the layouts and partials shared by
+// every page. A missing views folder has no templates.
+func findTemplates(fsys fs.FS) (pages, shared []string, err error) {
+	err = fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		switch {
+		case strings.HasSuffix(file, pageSuffix):
+			pages = append(pages, file)
+		case strings.HasSuffix(file, layoutSuffix), strings.HasSuffix(file, partialSuffix):
+			shared = append(shared, file)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	return pages, shared, err
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"reflect"
+
+	"github.com/CloudyKit/jet/v6"
+)
+
+// JetEngine renders Jet templates, views/<name>.jet
+type JetEngine struct {
+	// UseCache keeps parsed templates; without it jet runs in development
+	// mode and reads them again on every request
+	UseCache bool
+
+	// Set is the jet.Set templates are read from, made by Load
+	Set *jet.Set
+
+	funcs map[string]interface{}
+	views fs.FS
+}
+
+var _ Engine = (*JetEngine)(nil)
+
+// Load creates the Set for views
+func (e *JetEngine) Load(views fs.FS) error {
+	e.views = views
+
+	var opts []jet.Option
+	if !e.UseCache {
+		opts = append(opts, jet.InDevelopmentMode())
+	}
+	e.Set = jet.NewSet(NewFSLoader(views), opts...)
+
+	for name, fn := range e.funcs {
+		e.Set.AddGlobal(name, fn)
+	}
+	return nil
+}
+
+// Reload creates the Set again, dropping the templates it had parsed. A
+// Set made by the application, rather than by Load, is left alone.
+func (e *JetEngine) Reload() error {
+	if e.views == nil {
+		return nil
+	}
+	return e.Load(e.views)
+}
+
+// AddFuncs adds the functions as globals, which jet templates call like any
+// other function
+func (e *JetEngine) AddFuncs(funcs map[string]interface{}) {
+	if e.funcs == nil {
+		e.funcs = make(map[string]interface{})
+	}
+	for name, fn := range funcs {
+		e.funcs[name] = fn
+		if e.Set != nil {
+			e.Set.AddGlobal(name, fn)
+		}
+	}
+}
+
+// Extension is .jet
+func (e *JetEngine) Extension() string {
+	return ".jet"
+}
+
+// Render executes the template for view. Variables may be a jet.VarMap,
+// which is copied rather than changed, or a map[string]interface{}.
+func (e *JetEngine) Render(w io.Writer, r *http.Request, view string, variables interface{}, data *TemplateData) error {
+	vars := make(jet.VarMap)
+	switch v := variables.(type) {
+	case jet.VarMap:
+		for name, value := range v {
+			vars[name] = value
+		}
+	case map[string]interface{}:
+		for name, value := range v {
+			vars[name] = reflect.ValueOf(value)
+		}
+	}
+	for name, fn := range requestFuncs(r, data) {
+		vars.Set(name, fn)
+	}
+
+	t, err := e.Set.GetTemplate(view + ".jet")
+	if err != nil {
+		return err
+	}
+
+	return t.Execute(w, vars, data)
+}
@@ -0,0 +1,40 @@
+package render
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/CloudyKit/jet/v6"
+)
+
+// FSLoader is a jet.Loader which reads templates from an fs.FS, such as
+// an embed.FS, instead of the OS file system
+type FSLoader struct {
+	fsys fs.FS
+}
+
+var _ jet.Loader = (*FSLoader)(nil)
+
+// NewFSLoader returns a jet.Loader for the templates in fsys
+func NewFSLoader(fsys fs.FS) *FSLoader {
+	return &FSLoader{fsys: fsys}
+}
+
+// Exists reports whether a template exists at templatePath
+func (l *FSLoader) Exists(templatePath string) bool {
+	info, err := fs.Stat(l.fsys, fsPath(templatePath))
+	return err == nil && !info.IsDir()
+}
+
+// Open returns the contents of the template at templatePath
+func (l *FSLoader) Open(templatePath string) (io.ReadCloser, error) {
+	return l.fsys.Open(fsPath(templatePath))
+}
+
+// fsPath turns jet's absolute, slash separated template paths into the
+// unrooted form fs.FS expects
+func fsPath(templatePath string) string {
+	return strings.TrimPrefix(path.Clean("/"+templatePath), "/")
+}
@@ -0,0 +1,22 @@
+package render
+
+import (
+	"context"
+	"net/http"
+)
+
+type cspNonceKey struct{}
+
+// WithCSPNonce returns a copy of ctx carrying the request's
+// Content-Security-Policy nonce
+func WithCSPNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, cspNonceKey{}, nonce)
+}
+
+// CSPNonce returns the Content-Security-Policy nonce for the request, or
+// an empty string when the policy does not use one. Inline scripts and
+// styles carrying it as their nonce attribute are allowed to run.
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
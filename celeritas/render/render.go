@@ -4,9 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
-	"log"
+	"io/fs"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/CloudyKit/jet/v6"
 	"github.com/alexedwards/scs/v2"
@@ -20,7 +24,18 @@ type Render struct {
 	Port       string
 	ServerName string
 	JetViews   *jet.Set
+	Views      fs.FS
 	Session    *scs.SessionManager
+
+	// Funcs are added to the templates of every engine
+	Funcs template.FuncMap
+
+	// UseCache lets engines keep parsed templates, rather than reading
+	// them again on every request
+	UseCache bool
+
+	enginesMu sync.Mutex
+	engines   map[string]Engine
 }
 
 type TemplateData struct {
@@ -33,6 +48,13 @@ type TemplateData struct {
 	Port            string
 	ServerName      string
 	Secure          bool
+	CSPNonce        string
+	Flash           string
+	Error           string
+	Warning         string
+	Flashes         []FlashMessage
+	Errors          map[string]string
+	OldInput        url.Values
 }
 
 func (c *Render) defaultData(td *TemplateData, r *http.Request) *TemplateData {
@@ -40,27 +62,31 @@ func (c *Render) defaultData(td *TemplateData, r *http.Request) *TemplateData {
 	td.ServerName = c.ServerName
 	td.CSRFToken = nosurf.Token(r)
 	td.Port = c.Port
+	td.CSPNonce = CSPNonce(r)
 	if c.Session.Exists(r.Context(), "userID") {
 		td.IsAuthenticated = true
 	}
+	addFlashes(td, PopFlashes(c.Session, r.Context()))
+	addFormInput(td, c.Session, r.Context())
 	return td
 }
 
+// Page renders a view with the engine whose extension it carries
+// ("home.jet", "home.page.tmpl"), or else with the configured Renderer. If
+// the configured engine has no template for the view but another engine
+// does, that one is used, so that a project can mix engines.
 func (c *Render) Page(w http.ResponseWriter, r *http.Request, view string, variables, data interface{}) error {
-	switch strings.ToLower(c.Renderer) {
-	case "go":
-		return c.GoPage(w, r, view, data)
-	case "jet":
-		return c.JetPage(w, r, view, variables, data)
+	name, view, err := c.engineFor(view)
+	if err != nil {
+		return err
 	}
-	return errors.New("no rendering engine specified")
+	return c.render(w, r, name, view, variables, data)
 }
 
-// GoPage renders a standard Go template
-func (c *Render) GoPage(w http.ResponseWriter, r *http.Request, view string, data interface{}) error {
-	tmpl, err := template.ParseFiles(fmt.Sprintf("%s/views/%s.page.tmpl", c.RootPath, view))
+// render renders a view with the named engine
+func (c *Render) render(w http.ResponseWriter, r *http.Request, name, view string, variables, data interface{}) error {
+	e, err := c.Engine(name)
 	if err != nil {
-		fmt.Println(err)
 		return err
 	}
 
@@ -69,42 +95,115 @@ func (c *Render) GoPage(w http.ResponseWriter, r *http.Request, view string, dat
 		td = data.(*TemplateData)
 	}
 
-	err = tmpl.Execute(w, &td)
-	if err != nil {
-		fmt.Println(err)
-		return err
+	td = c.defaultData(td, r)
+
+	return e.Render(w, r, view, variables, td)
+}
+
+// engineFor picks the engine for a view, returning the view without any
+// engine extension
+func (c *Render) engineFor(view string) (string, string, error) {
+	c.enginesMu.Lock()
+	defer c.enginesMu.Unlock()
+
+	if err := c.load(); err != nil {
+		return "", "", err
+	}
+	names := Engines()
+
+	// an explicit extension; the longest wins, so that .page.tmpl is not
+	// taken for a shorter .tmpl
+	found, ext := "", ""
+	for _, name := range names {
+		e := c.engines[name]
+		if strings.HasSuffix(view, e.Extension()) && len(e.Extension()) > len(ext) {
+			found, ext = name, e.Extension()
+		}
+	}
+	if found != "" {
+		return found, strings.TrimSuffix(view, ext), nil
 	}
 
-	return nil
+	configured := strings.ToLower(c.Renderer)
+	if configured != "" {
+		if _, ok := c.engines[configured]; !ok {
+			return "", "", fmt.Errorf("unknown rendering engine %q", c.Renderer)
+		}
+		if c.exists(c.engines[configured], view) {
+			return configured, view, nil
+		}
+	}
+
+	for _, name := range names {
+		if c.exists(c.engines[name], view) {
+			return name, view, nil
+		}
+	}
+
+	if configured == "" {
+		return "", "", errors.New("no rendering engine specified")
+	}
+	// let the configured engine report the missing template
+	return configured, view, nil
 }
 
-// JetPage renders a template using the Jet templating engine
-func (c *Render) JetPage(w http.ResponseWriter, r *http.Request, templateName string, variables, data interface{}) error {
-	var vars jet.VarMap
+// exists reports whether the engine has a template for the view
+func (c *Render) exists(e Engine, view string) bool {
+	info, err := fs.Stat(c.viewsFS(), view+e.Extension())
+	return err == nil && !info.IsDir()
+}
 
-	if variables == nil {
-		vars = make(jet.VarMap)
-	} else {
-		vars = variables.(jet.VarMap)
+// viewsFS returns Views, or the views folder under RootPath when it is not
+// set
+func (c *Render) viewsFS() fs.FS {
+	if c.Views != nil {
+		return c.Views
 	}
+	return os.DirFS(filepath.Join(c.RootPath, "views"))
+}
 
-	td := &TemplateData{}
-	if data != nil {
-		td = data.(*TemplateData)
+// requestFuncs are the template functions which depend on the current
+// request and its data, made available to both Go and Jet templates
+func requestFuncs(r *http.Request, td *TemplateData) template.FuncMap {
+	return template.FuncMap{
+		"cspNonce": func() string { return CSPNonce(r) },
+		"old":      td.old,
+		"error":    td.fieldError,
+		"hasError": td.hasError,
 	}
+}
 
-	td = c.defaultData(td, r)
+// GoPage renders a standard Go template, views/<view>.page.tmpl, along with
+// every layout and partial
+func (c *Render) GoPage(w http.ResponseWriter, r *http.Request, view string, variables, data interface{}) error {
+	return c.render(w, r, "go", view, variables, data)
+}
 
-	t, err := c.JetViews.GetTemplate(fmt.Sprintf("%s.jet", templateName))
-	if err != nil {
-		log.Println(err)
-		return err
+// addVariables copies the variables given to Page into td.Data, so that a
+// handler can pass the same variables whichever renderer is configured
+func addVariables(td *TemplateData, variables interface{}) {
+	if variables == nil {
+		return
+	}
+	if td.Data == nil {
+		td.Data = make(map[string]interface{})
 	}
 
-	if err = t.Execute(w, vars, td); err != nil {
-		log.Println(err)
-		return err
+	switch vars := variables.(type) {
+	case jet.VarMap:
+		for name, value := range vars {
+			if value.IsValid() {
+				td.Data[name] = value.Interface()
+			}
+		}
+	case map[string]interface{}:
+		for name, value := range vars {
+			td.Data[name] = value
+		}
 	}
+}
 
-	return nil
+// JetPage renders a template using the Jet templating engine
+func (c *Render) JetPage(w http.ResponseWriter, r *http.Request, templateName string, variables, data interface{}) error {
+	return c.render(w, r, "jet", templateName, variables, data)
 }
@@ -1,9 +1,14 @@
 package render
 
 import (
-	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/CloudyKit/jet/v6"
 )
 
 var pageData = []struct {
@@ -22,7 +27,7 @@ var pageData = []struct {
 
 func TestRender_Page(t *testing.T) {
 	for _, e := range pageData {
-		r, err := http.NewRequest("GET", "/some-url", nil)
+		r, err := getRequest("GET", "/some-url")
 		if err != nil {
 			t.Error(err)
 		}
@@ -42,3 +47,221 @@ func TestRender_Page(t *testing.T) {
 		}
 	}
 }
+
+func TestRender_PageFromFS(t *testing.T) {
+	fsys := os.DirFS("./testdata/views")
+	fsRenderer := Render{
+		JetViews: jet.NewSet(NewFSLoader(fsys), jet.InDevelopmentMode()),
+		Views:    fsys,
+		Session:  testSession,
+	}
+
+	for _, renderer := range []string{"go", "jet"} {
+		r, err := getRequest("GET", "/some-url")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		fsRenderer.Renderer = renderer
+
+		err = fsRenderer.Page(w, r, "home", nil, nil)
+		if err != nil {
+			t.Errorf("%s: error rendering template from fs.FS: %s", renderer, err)
+		}
+
+		err = fsRenderer.Page(w, r, "no-such-file", nil, nil)
+		if err == nil {
+			t.Errorf("%s: no error rendering a missing template from fs.FS", renderer)
+		}
+	}
+}
+
+func TestRender_CSPNonce(t *testing.T) {
+	for _, renderer := range []string{"go", "jet"} {
+		r, err := getRequest("GET", "/some-url")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = r.WithContext(WithCSPNonce(r.Context(), "abc123"))
+		w := httptest.NewRecorder()
+		testRenderer.Renderer = renderer
+		testRenderer.RootPath = "./testdata"
+
+		err = testRenderer.Page(w, r, "nonce", nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", renderer, err)
+		}
+		if got := w.Body.String(); !strings.Contains(got, `nonce="abc123"`) {
+			t.Errorf("%s: nonce missing from %q", renderer, got)
+		}
+	}
+}
+
+func TestRender_GoLayoutsAndPartials(t *testing.T) {
+	r, err := getRequest("GET", "/some-url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	testRenderer.Renderer = "go"
+	testRenderer.RootPath = "./testdata"
+	testRenderer.ServerName = "example.test"
+
+	vars := make(jet.VarMap)
+	vars.Set("greeting", "Hello from the page")
+
+	err = testRenderer.Page(w, r, "layout", vars, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := w.Body.String()
+	for _, want := range []string{"<html><body>", "<h1>Hello from the page</h1>", "<footer>example.test</footer>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestRender_JetVariablesLeftAlone(t *testing.T) {
+	testRenderer.Renderer = "jet"
+	testRenderer.RootPath = "./testdata"
+
+	vars := make(jet.VarMap)
+	vars.Set("greeting", "Hello")
+
+	for i := 0; i < 2; i++ {
+		r, err := getRequest("GET", "/some-url")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = testRenderer.Page(httptest.NewRecorder(), r, "home", vars, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(vars) != 1 {
+		t.Errorf("the handler's variables were changed: %v", vars)
+	}
+}
+
+func TestRender_GoTemplateCache(t *testing.T) {
+	fsys := fstest.MapFS{
+		"home.page.tmpl":               &fstest.MapFile{Data: []byte(`{{ template "base" . }}{{ define "content" }}first{{ end }}`)},
+		"layouts/base.layout.tmpl":     &fstest.MapFile{Data: []byte(`{{ define "base" }}[{{ block "content" . }}{{ end }}]{{ end }}`)},
+		"errors/503.page.tmpl":         &fstest.MapFile{Data: []byte(`down`)},
+		"partials/unused.partial.tmpl": &fstest.MapFile{Data: []byte(`{{ define "unused" }}{{ end }}`)},
+	}
+	cached := Render{Renderer: "go", Views: fsys, Session: testSession, UseCache: true}
+
+	if err := cached.Load(); err != nil {
+		t.Fatal(err)
+	}
+	e, err := cached.Engine("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := e.(*GoEngine).templateCache["errors/503"]; !ok {
+		t.Error("page in a subfolder missing from the cache")
+	}
+
+	render := func(rend *Render) string {
+		r, err := getRequest("GET", "/some-url")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		if err = rend.Page(w, r, "home", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		return w.Body.String()
+	}
+
+	if got := render(&cached); got != "[first]" {
+		t.Fatalf("expected [first], got %q", got)
+	}
+
+	fsys["home.page.tmpl"] = &fstest.MapFile{Data: []byte(`{{ template "base" . }}{{ define "content" }}second{{ end }}`)}
+
+	if got := render(&cached); got != "[first]" {
+		t.Errorf("cached template was parsed again: got %q", got)
+	}
+
+	uncached := Render{Renderer: "go", Views: fsys, Session: testSession}
+	if got := render(&uncached); got != "[second]" {
+		t.Errorf("expected the changed template without the cache, got %q", got)
+	}
+}
+
+func TestRender_GoPageDefaultData(t *testing.T) {
+	r, err := getRequest("GET", "/some-url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSession.Put(r.Context(), "userID", 1)
+
+	fsys := fstest.MapFS{
+		"auth.page.tmpl": &fstest.MapFile{Data: []byte(`{{ if .IsAuthenticated }}in{{ else }}out{{ end }} {{ .Port }}`)},
+	}
+	rend := Render{Renderer: "go", Views: fsys, Session: testSession, Port: "4000"}
+
+	w := httptest.NewRecorder()
+	err = rend.Page(w, r, "auth", nil, &TemplateData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Body.String(); got != "in 4000" {
+		t.Errorf("expected the default template data, got %q", got)
+	}
+}
+
+func TestRender_Flashes(t *testing.T) {
+	r, err := getRequest("GET", "/some-url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	AddFlash(testSession, r.Context(), LevelFlash, "Saved")
+	AddFlash(testSession, r.Context(), LevelWarning, "Check your email")
+
+	fsys := fstest.MapFS{
+		"flash.page.tmpl": &fstest.MapFile{Data: []byte(`{{ .Flash }}|{{ .Warning }}|{{ len .Flashes }}`)},
+	}
+	rend := Render{Renderer: "go", Views: fsys, Session: testSession}
+
+	for _, expected := range []string{"Saved|Check your email|2", "||0"} {
+		w := httptest.NewRecorder()
+		if err = rend.Page(w, r, "flash", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := w.Body.String(); got != expected {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestRender_FormInput(t *testing.T) {
+	fsys := fstest.MapFS{
+		"form.jet":       &fstest.MapFile{Data: []byte(`{{ old("email") }}|{{ old("name", "Jo") }}|{{ error("email") }}|{{ hasError() }}|{{ hasError("name") }}`)},
+		"form.page.tmpl": &fstest.MapFile{Data: []byte(`{{ old "email" }}|{{ old "name" "Jo" }}|{{ error "email" }}|{{ hasError }}|{{ hasError "name" }}`)},
+	}
+
+	for _, view := range []string{"form.jet", "form.page.tmpl"} {
+		r, err := getRequest("GET", "/some-url")
+		if err != nil {
+			t.Fatal(err)
+		}
+		PutFormInput(testSession, r.Context(), map[string]string{"email": "Invalid email address"}, url.Values{"email": {"me@"}})
+
+		rend := Render{Views: fsys, Session: testSession}
+		w := httptest.NewRecorder()
+		if err = rend.Page(w, r, view, nil, nil); err != nil {
+			t.Fatalf("%s: %s", view, err)
+		}
+
+		expected := "me@|Jo|Invalid email address|true|false"
+		if got := w.Body.String(); got != expected {
+			t.Errorf("%s: expected %q, got %q", view, expected, got)
+		}
+	}
+}
@@ -1,18 +1,39 @@
 package render
 
 import (
+	"net/http"
 	"os"
 	"testing"
 
 	"github.com/CloudyKit/jet/v6"
+	"github.com/alexedwards/scs/v2"
 )
 
 var views = jet.NewSet(jet.NewOSFileSystemLoader("./testdata/views"), jet.InDevelopmentMode())
 
+var testSession = scs.New()
+
 var testRenderer = Render{
 	Renderer: "",
 	RootPath: "",
 	JetViews: views,
+	Session:  testSession,
+}
+
+// getRequest returns a request carrying a loaded session, as it would be
+// after the SessionLoad middleware
+func getRequest(method, target string) (*http.Request, error) {
+	r, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := testSession.Load(r.Context(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.WithContext(ctx), nil
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,135 @@
+package celeritas
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// The actions a resource handler may implement. Resource and APIResource
+// register a route for each one the handler has, and skip the others.
+type (
+	// Indexer lists the resource: GET /users
+	Indexer interface {
+		Index(w http.ResponseWriter, r *http.Request)
+	}
+	// Creator shows the form for a new item: GET /users/create
+	Creator interface {
+		Create(w http.ResponseWriter, r *http.Request)
+	}
+	// Storer saves a new item: POST /users
+	Storer interface {
+		Store(w http.ResponseWriter, r *http.Request)
+	}
+	// Shower shows one item: GET /users/{id}
+	Shower interface {
+		Show(w http.ResponseWriter, r *http.Request)
+	}
+	// Editor shows the form for an item: GET /users/{id}/edit
+	Editor interface {
+		Edit(w http.ResponseWriter, r *http.Request)
+	}
+	// Updater saves an item: PUT or PATCH /users/{id}
+	Updater interface {
+		Update(w http.ResponseWriter, r *http.Request)
+	}
+	// Destroyer deletes an item: DELETE /users/{id}
+	Destroyer interface {
+		Destroy(w http.ResponseWriter, r *http.Request)
+	}
+)
+
+// Resource registers the standard routes for the actions handler
+// implements, and names them after the pattern, e.g. users.index,
+// users.show. The item id is available as chi.URLParam(r, "id").
+//
+//	a.App.Resource("/users", &handlers.UsersResource{Handlers: a.Handlers})
+//
+// HTML forms can reach Update and Destroy by posting a _method field of
+// PUT, PATCH or DELETE (see MethodOverride).
+func (c *Celeritas) Resource(pattern string, handler interface{}) {
+	c.resource(c.Routes, pattern, handler, true)
+}
+
+// APIResource is Resource without the Create and Edit form routes
+func (c *Celeritas) APIResource(pattern string, handler interface{}) {
+	c.resource(c.Routes, pattern, handler, false)
+}
+
+func (c *Celeritas) resource(router chi.Router, pattern string, handler interface{}, forms bool) {
+	pattern = "/" + strings.Trim(pattern, "/")
+	item := pattern + "/{id}"
+	name := resourceName(pattern)
+
+	route := func(method, path, action string, h http.HandlerFunc) {
+		router.Method(method, path, h)
+		c.NameRoute(name+"."+action, path)
+	}
+
+	if h, ok := handler.(Indexer); ok {
+		route(http.MethodGet, pattern, "index", h.Index)
+	}
+	if h, ok := handler.(Creator); ok && forms {
+		route(http.MethodGet, pattern+"/create", "create", h.Create)
+	}
+	if h, ok := handler.(Storer); ok {
+		route(http.MethodPost, pattern, "store", h.Store)
+	}
+	if h, ok := handler.(Shower); ok {
+		route(http.MethodGet, item, "show", h.Show)
+	}
+	if h, ok := handler.(Editor); ok && forms {
+		route(http.MethodGet, item+"/edit", "edit", h.Edit)
+	}
+	if h, ok := handler.(Updater); ok {
+		route(http.MethodPut, item, "update", h.Update)
+		router.Patch(item, h.Update)
+	}
+	if h, ok := handler.(Destroyer); ok {
+		route(http.MethodDelete, item, "destroy", h.Destroy)
+	}
+}
+
+// resourceName turns /admin/users into admin.users, leaving out any
+// parameters of a nested resource such as /users/{userID}/posts
+func resourceName(pattern string) string {
+	var parts []string
+	for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// MethodOverride lets HTML forms, which can only GET and POST, reach PUT,
+// PATCH and DELETE routes: a POST with a _method form field, or an
+// X-HTTP-Method-Override header, is routed as that method instead.
+//
+//	<form method="post" action="{{ route("users.update", user.ID) }}">
+//	    <input type="hidden" name="_method" value="PUT">
+func (c *Celeritas) MethodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			method := r.Header.Get("X-HTTP-Method-Override")
+			if method == "" && isFormRequest(r) {
+				method = r.PostFormValue("_method")
+			}
+
+			switch method = strings.ToUpper(method); method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				r.Method = method
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isFormRequest reports whether the request body is an HTML form, so that
+// other bodies, such as JSON, are left for the handler to read
+func isFormRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/x-www-form-urlencoded") ||
+		strings.HasPrefix(contentType, "multipart/form-data")
+}
@@ -0,0 +1,71 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type testResource struct{}
+
+func (testResource) Index(w http.ResponseWriter, r *http.Request)  { w.Write([]byte("index")) }
+func (testResource) Create(w http.ResponseWriter, r *http.Request) { w.Write([]byte("create")) }
+func (testResource) Store(w http.ResponseWriter, r *http.Request)  { w.Write([]byte("store")) }
+func (testResource) Show(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("show " + chi.URLParam(r, "id")))
+}
+func (testResource) Update(w http.ResponseWriter, r *http.Request)  { w.Write([]byte("update")) }
+func (testResource) Destroy(w http.ResponseWriter, r *http.Request) { w.Write([]byte("destroy")) }
+
+func TestCeleritas_Resource(t *testing.T) {
+	c := &Celeritas{Routes: chi.NewRouter()}
+	c.Routes.Use(c.MethodOverride)
+	c.Resource("/admin/users", testResource{})
+	c.APIResource("/api/users", testResource{})
+
+	var tests = []struct {
+		method   string
+		target   string
+		form     url.Values
+		expected string
+	}{
+		{"GET", "/admin/users", nil, "index"},
+		{"GET", "/admin/users/create", nil, "create"},
+		{"POST", "/admin/users", nil, "store"},
+		{"GET", "/admin/users/7", nil, "show 7"},
+		{"PUT", "/admin/users/7", nil, "update"},
+		{"PATCH", "/admin/users/7", nil, "update"},
+		{"DELETE", "/admin/users/7", nil, "destroy"},
+		{"POST", "/admin/users/7", url.Values{"_method": {"delete"}}, "destroy"},
+		{"POST", "/admin/users/7", url.Values{"_method": {"PUT"}}, "update"},
+		{"GET", "/api/users/create", nil, "show create"},
+		{"GET", "/admin/users/7/edit", nil, "404 page not found\n"},
+	}
+
+	for _, e := range tests {
+		var r *http.Request
+		if e.form != nil {
+			r = httptest.NewRequest(e.method, e.target, strings.NewReader(e.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			r = httptest.NewRequest(e.method, e.target, nil)
+		}
+		w := httptest.NewRecorder()
+		c.Routes.ServeHTTP(w, r)
+
+		if w.Body.String() != e.expected {
+			t.Errorf("%s %s: expected %q, got %q", e.method, e.target, e.expected, w.Body.String())
+		}
+	}
+
+	if got := c.URL("admin.users.show", 3); got != "/admin/users/3" {
+		t.Errorf("unexpected URL %q", got)
+	}
+	if _, err := c.BuildURL("api.users.create"); err == nil {
+		t.Error("API resources should not have a create route")
+	}
+}
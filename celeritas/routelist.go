@@ -0,0 +1,138 @@
+package celeritas
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RouteListEnv names the environment variable which makes ListenAndServe
+// write the route list, as JSON, to the file it names and return instead
+// of serving. The celeritas routes command uses it. The application is then
+// set up without connecting to its database, Redis or Badger, so that its
+// routes can be listed while those are down or the application is running.
+const RouteListEnv = "CELERITAS_ROUTE_LIST"
+
+// listingRoutes reports whether the application only lists its routes
+func listingRoutes() bool {
+	return os.Getenv(RouteListEnv) != ""
+}
+
+// anyMethod stands for a route registered with Handle, which answers
+// every method
+const anyMethod = "ANY"
+
+// RouteInfo describes one registered route
+type RouteInfo struct {
+	Method     string   `json:"method"`
+	Pattern    string   `json:"pattern"`
+	Name       string   `json:"name,omitempty"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware,omitempty"`
+}
+
+// RouteList returns every route of the application, sorted by pattern and
+// method, with its name (see NameRoute), handler and middleware chain
+func (c *Celeritas) RouteList() ([]RouteInfo, error) {
+	names := make(map[string]string)
+	c.routeNamesMu.RLock()
+	for name, pattern := range c.routeNames {
+		names[pattern] = name
+	}
+	c.routeNamesMu.RUnlock()
+
+	var routes []RouteInfo
+	err := chi.Walk(c.Routes, func(method, pattern string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		info := RouteInfo{
+			Method:  method,
+			Pattern: pattern,
+			Name:    names[pattern],
+			Handler: funcName(handler),
+		}
+		for _, mw := range middlewares {
+			info.Middleware = append(info.Middleware, funcName(mw))
+		}
+		routes = append(routes, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routes = collapseAnyMethod(routes)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes, nil
+}
+
+// collapseAnyMethod replaces the entry chi keeps for each method of a
+// route registered with Handle by a single ANY entry
+func collapseAnyMethod(routes []RouteInfo) []RouteInfo {
+	const methods = 9 // CONNECT, DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT and TRACE
+
+	count := make(map[string]int)
+	for _, route := range routes {
+		count[route.Pattern+" "+route.Handler]++
+	}
+
+	var collapsed []RouteInfo
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		key := route.Pattern + " " + route.Handler
+		if count[key] < methods {
+			collapsed = append(collapsed, route)
+			continue
+		}
+		if !seen[key] {
+			seen[key] = true
+			route.Method = anyMethod
+			collapsed = append(collapsed, route)
+		}
+	}
+	return collapsed
+}
+
+// funcName returns the short name of the function behind a handler or
+// middleware, e.g. handlers.(*Handlers).Home
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return reflect.TypeOf(fn).String()
+	}
+
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "unknown"
+	}
+
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// writeRouteList writes the route list to file, as JSON
+func (c *Celeritas) writeRouteList(file string) error {
+	routes, err := c.RouteList()
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(routes, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, out, 0644)
+}
@@ -0,0 +1,115 @@
+package celeritas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func testRouteHandler(w http.ResponseWriter, r *http.Request) {}
+
+func TestCeleritas_RouteList(t *testing.T) {
+	c := &Celeritas{Routes: chi.NewRouter()}
+	c.Routes.Use(c.MethodOverride)
+	c.Routes.Get("/users/{id}", testRouteHandler)
+	c.NameRoute("user.show", "/users/{id}")
+	c.Routes.With(c.ConditionalGet).Post("/users", testRouteHandler)
+	c.Routes.Handle("/public/*", http.NotFoundHandler())
+	c.Routes.Route("/api", func(r chi.Router) {
+		r.Delete("/users/{id}", testRouteHandler)
+	})
+
+	routes, err := c.RouteList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, route := range routes {
+		got = append(got, route.Method+" "+route.Pattern+" "+route.Name)
+	}
+	expected := []string{"DELETE /api/users/{id} ", "ANY /public/* ", "POST /users ", "GET /users/{id} user.show"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	show := routes[3]
+	if show.Handler != "celeritas.testRouteHandler" {
+		t.Errorf("unexpected handler name %q", show.Handler)
+	}
+	if len(show.Middleware) != 1 || show.Middleware[0] != "celeritas.(*Celeritas).MethodOverride" {
+		t.Errorf("unexpected middleware %q", show.Middleware)
+	}
+	if post := routes[2]; len(post.Middleware) != 2 || post.Middleware[1] != "celeritas.(*Celeritas).ConditionalGet" {
+		t.Errorf("inline middleware missing from %q", post.Middleware)
+	}
+}
+
+func TestCeleritas_ListenAndServeRouteList(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "routes.json")
+	t.Setenv(RouteListEnv, file)
+
+	// none of these are reachable, and none should be connected to
+	cfg := &Config{
+		Port:        4000,
+		Renderer:    "jet",
+		SessionType: "postgres",
+		Cache:       "badger",
+		Database: DatabaseConfig{
+			Type:           "postgres",
+			Host:           "127.0.0.1",
+			Port:           1,
+			User:           "nobody",
+			Name:           "nothing",
+			SSLMode:        "disable",
+			ConnectTimeout: time.Minute,
+		},
+	}
+	app, err := NewApp(WithRootPath(root), WithConfig(cfg), WithViews(fstest.MapFS{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Routes.Get("/widgets", testRouteHandler)
+
+	hookRan := false
+	app.OnShutdown(func(ctx context.Context) error {
+		hookRan = true
+		return nil
+	})
+
+	err = app.ListenAndServe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hookRan {
+		t.Error("the application was not shut down after listing its routes")
+	}
+	if _, err = os.Stat(filepath.Join(root, "tmp", "badger")); !os.IsNotExist(err) {
+		t.Error("badger was opened to list the routes")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var routes []RouteInfo
+	if err = json.Unmarshal(data, &routes); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, route := range routes {
+		found = found || route.Pattern == "/widgets"
+	}
+	if !found {
+		t.Errorf("/widgets missing from %s", data)
+	}
+}
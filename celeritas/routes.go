@@ -11,17 +11,69 @@ func (c *Celeritas) routes() http.Handler {
 	mux := chi.NewRouter()
 	mux.Use(middleware.RequestID)
 	mux.Use(middleware.RealIP)
-	if c.Debug {
-		mux.Use(middleware.Logger)
-	}
 	mux.Use(middleware.Recoverer)
 
+	///////////////////////////////////////////////////////////////
+	// Security headers, CSP nonces and HSTS when serving securely
+	///////////////////////////////////////////////////////////////
+	if c.Config.Security.Headers {
+		mux.Use(c.SecurityHeaders)
+	} else if c.Config.Secure {
+		mux.Use(c.HSTS)
+	}
+
+	///////////////////////////////////////////////////////////////
+	// Liveness and readiness probes, before sessions and CSRF
+	///////////////////////////////////////////////////////////////
+	if c.Config.Health.Enabled {
+		mux.Use(c.Health)
+	}
+
+	///////////////////////////////////////////////////////////////
+	// Prometheus metrics endpoint and request instrumentation
+	///////////////////////////////////////////////////////////////
+	if c.Config.Metrics.Enabled {
+		mux.Use(c.MetricsEndpoint)
+		mux.Use(c.RecordMetrics)
+	}
+
+	///////////////////////////////////////////////////////////////
+	// Compress responses with brotli or gzip
+	///////////////////////////////////////////////////////////////
+	if c.Config.Compression.Enabled {
+		mux.Use(c.Compress)
+	}
+
+	///////////////////////////////////////////////////////////////
+	// Cross-origin requests, answering preflights before sessions
+	///////////////////////////////////////////////////////////////
+	if len(c.Config.CORS.AllowedOrigins) > 0 {
+		mux.Use(c.CORS)
+	}
+
+	///////////////////////////////////////////////////////////////
+	// Answer 503 while the application is down for maintenance
+	///////////////////////////////////////////////////////////////
+	mux.Use(c.Maintenance)
+
 	///////////////////////////////////////////////////////////////
 	// Use middleware to handle Http Sessions
 	///////////////////////////////////////////////////////////////
 	mux.Use(c.SessionLoad)
 	///////////////////////////////////////////////////////////////
 
+	///////////////////////////////////////////////////////////////
+	// Request scoped logger and access log (at debug level)
+	///////////////////////////////////////////////////////////////
+	mux.Use(c.LogRequests)
+	///////////////////////////////////////////////////////////////
+
+	///////////////////////////////////////////////////////////////
+	// Let HTML forms reach PUT, PATCH and DELETE routes (_method)
+	///////////////////////////////////////////////////////////////
+	mux.Use(c.MethodOverride)
+	///////////////////////////////////////////////////////////////
+
 	///////////////////////////////////////////////////////////////
 	// Use NoSurf package to manage CSRF
 	///////////////////////////////////////////////////////////////
@@ -0,0 +1,46 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/leetrent/celeritas/render"
+)
+
+func TestCeleritas_SecurityHeaders(t *testing.T) {
+	c := &Celeritas{Config: Config{Security: SecurityConfig{
+		Headers:        true,
+		FrameOptions:   "DENY",
+		ReferrerPolicy: "no-referrer",
+		CSP:            "script-src 'self' 'nonce-{nonce}'",
+	}}}
+
+	var nonces []string
+	handler := c.SecurityHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, render.CSPNonce(r))
+	}))
+
+	var headers []http.Header
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		headers = append(headers, w.Header())
+	}
+
+	if headers[0].Get("X-Content-Type-Options") != "nosniff" || headers[0].Get("X-Frame-Options") != "DENY" || headers[0].Get("Referrer-Policy") != "no-referrer" {
+		t.Errorf("unexpected headers %v", headers[0])
+	}
+	if headers[0].Get("Strict-Transport-Security") != "" {
+		t.Error("HSTS sent when not serving securely")
+	}
+
+	if nonces[0] == "" || nonces[0] == nonces[1] {
+		t.Fatalf("expected a fresh nonce per request, got %q", nonces)
+	}
+	csp := headers[0].Get("Content-Security-Policy")
+	if csp != "script-src 'self' 'nonce-"+nonces[0]+"'" || strings.Contains(csp, "{nonce}") {
+		t.Errorf("unexpected policy %q", csp)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/alexedwards/scs/mysqlstore"
 	"github.com/alexedwards/scs/postgresstore"
 	"github.com/alexedwards/scs/redisstore"
+	"github.com/alexedwards/scs/sqlite3store"
 	"github.com/alexedwards/scs/v2"
 	"github.com/gomodule/redigo/redis"
 )
@@ -61,6 +62,8 @@ func (c *Session) InitSession() *scs.SessionManager {
 		session.Store = mysqlstore.New(c.DBPool)
 	case "postgres", "postgresql":
 		session.Store = postgresstore.New(c.DBPool)
+	case "sqlite", "sqlite3":
+		session.Store = sqlite3store.New(c.DBPool)
 	default: // cookie
 	}
 
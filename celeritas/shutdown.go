@@ -0,0 +1,152 @@
+package celeritas
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// ShutdownHook is a function the application wants run when celeritas
+// shuts down. The context expires when the shutdown timeout is reached.
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown registers a hook to run during shutdown. Hooks run in the
+// order they were registered, after in-flight requests have drained and
+// the scheduler has stopped, but before the cache and database
+// connections are closed, so they may still use them.
+func (c *Celeritas) OnShutdown(hook ShutdownHook) {
+	c.shutdownMu.Lock()
+	defer c.shutdownMu.Unlock()
+	c.shutdownHooks = append(c.shutdownHooks, hook)
+}
+
+// waitForShutdown blocks until the web server fails or the process
+// receives SIGINT or SIGTERM, and then shuts the application down.
+func (c *Celeritas) waitForShutdown(serverErrors <-chan error) error {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var serveErr error
+	select {
+	case err := <-serverErrors:
+		if !errors.Is(err, http.ErrServerClosed) {
+			serveErr = err
+		}
+	case sig := <-quit:
+		c.Logger.Info("shutting down", "signal", sig.String())
+	}
+
+	timeout := c.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := c.Shutdown(ctx); err != nil && serveErr == nil {
+		return err
+	}
+	return serveErr
+}
+
+// Shutdown gracefully stops the application: the web server stops
+// accepting new connections and waits for in-flight requests, the
+// scheduler is stopped, registered shutdown hooks run, and then the
+// Badger, Redis and database connections, including any read replica,
+// are closed, in that order. Connections passed in with WithDB or
+// WithCache are left open for the caller to close. Every step runs even
+// when an earlier one fails, and the errors are returned joined together.
+// Calling Shutdown more than once has no further effect.
+func (c *Celeritas) Shutdown(ctx context.Context) error {
+	c.shutdownMu.Lock()
+	if c.shutdownDone {
+		c.shutdownMu.Unlock()
+		return nil
+	}
+	c.shutdownDone = true
+	hooks := c.shutdownHooks
+	c.shutdownMu.Unlock()
+
+	var errs []error
+	record := func(err error) {
+		if err == nil {
+			return
+		}
+		errs = append(errs, err)
+		if c.Logger != nil {
+			c.Logger.Error("shutdown", "error", err)
+		}
+	}
+
+	//////////////////////////////////////////////////////////
+	// STOP ACCEPTING REQUESTS AND DRAIN IN-FLIGHT ONES
+	//////////////////////////////////////////////////////////
+	if c.server != nil {
+		record(c.server.Shutdown(ctx))
+	}
+	if c.redirectServer != nil {
+		record(c.redirectServer.Shutdown(ctx))
+	}
+
+	//////////////////////////////////////////////////////////
+	// STOP SCHEDULER AND WAIT FOR RUNNING JOBS
+	//////////////////////////////////////////////////////////
+	if c.Scheduler != nil {
+		select {
+		case <-c.Scheduler.Stop().Done():
+		case <-ctx.Done():
+			record(ctx.Err())
+		}
+	}
+
+	//////////////////////////////////////////////////////////
+	// RUN APPLICATION SHUTDOWN HOOKS
+	//////////////////////////////////////////////////////////
+	for _, hook := range hooks {
+		record(hook(ctx))
+	}
+
+	//////////////////////////////////////////////////////////
+	// FLUSH AND CLOSE BADGER CACHE
+	//////////////////////////////////////////////////////////
+	if c.badgerConn != nil && c.ownsBadger {
+		record(c.badgerConn.Sync())
+		record(c.badgerConn.Close())
+	}
+
+	//////////////////////////////////////////////////////////
+	// CLOSE REDIS POOL
+	//////////////////////////////////////////////////////////
+	if c.redisPool != nil && c.ownsRedis {
+		record(c.redisPool.Close())
+	}
+
+	//////////////////////////////////////////////////////////
+	// CLOSE DATABASE POOLS
+	//////////////////////////////////////////////////////////
+	if c.ownsDB {
+		if c.DB.Reader != nil && c.DB.Reader != c.DB.Pool {
+			record(c.DB.Reader.Close())
+		}
+		if c.DB.Pool != nil {
+			record(c.DB.Pool.Close())
+		}
+	}
+
+	//////////////////////////////////////////////////////////
+	// CLOSE LOG FILE
+	//////////////////////////////////////////////////////////
+	if c.logFile != nil {
+		record(c.logFile.Close())
+	}
+
+	return errors.Join(errs...)
+}
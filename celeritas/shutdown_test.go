@@ -0,0 +1,138 @@
+package celeritas
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gomodule/redigo/redis"
+	"github.com/leetrent/celeritas/cache"
+)
+
+func TestCeleritas_ShutdownLeavesInjectedConnections(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "injected.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	errNoRedis := errors.New("no redis here")
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return nil, errNoRedis }}
+	defer pool.Close()
+
+	app, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{Port: 4000, Renderer: "jet", SessionType: "cookie"}),
+		WithDB(db),
+		WithCache(&cache.RedisCache{Conn: pool, Prefix: "test"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.Ping(); err != nil {
+		t.Errorf("injected database was closed: %s", err)
+	}
+	// a closed pool refuses to dial at all
+	if err = pool.Get().Err(); !errors.Is(err, errNoRedis) {
+		t.Errorf("injected redis pool was closed: %v", err)
+	}
+
+	bdb, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bdb.Close()
+
+	app, err = NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{Port: 4000, Renderer: "jet", SessionType: "cookie"}),
+		WithCache(&cache.BadgerCache{Conn: bdb}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = app.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if bdb.IsClosed() {
+		t.Error("injected badger database was closed")
+	}
+}
+
+func TestCeleritas_ShutdownClosesOwnConnections(t *testing.T) {
+	cfg := &Config{
+		Port:        4000,
+		Renderer:    "jet",
+		SessionType: "cookie",
+		Database:    DatabaseConfig{Type: "sqlite", Name: filepath.Join(t.TempDir(), "own.db"), SSLMode: "disable"},
+	}
+	app, err := NewApp(WithRootPath(t.TempDir()), WithConfig(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = app.DB.Pool.Ping(); err == nil {
+		t.Error("database opened by celeritas was left open")
+	}
+}
+
+func TestCeleritas_ShutdownHooks(t *testing.T) {
+	cfg := &Config{
+		Port:        4000,
+		Renderer:    "jet",
+		SessionType: "cookie",
+		Database:    DatabaseConfig{Type: "sqlite", Name: filepath.Join(t.TempDir(), "hooks.db"), SSLMode: "disable"},
+	}
+	app, err := NewApp(WithRootPath(t.TempDir()), WithConfig(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errFirst := errors.New("first hook failed")
+	errThird := errors.New("third hook failed")
+	var ran []string
+
+	app.OnShutdown(func(ctx context.Context) error {
+		ran = append(ran, "first")
+		return errFirst
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		// hooks run before the database is closed
+		if err := app.DB.Pool.PingContext(ctx); err != nil {
+			t.Errorf("database closed before the hooks ran: %s", err)
+		}
+		ran = append(ran, "second")
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		ran = append(ran, "third")
+		return errThird
+	})
+
+	err = app.Shutdown(context.Background())
+	if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+		t.Errorf("expected the errors of both failing hooks, got %v", err)
+	}
+	if got := strings.Join(ran, ","); got != "first,second,third" {
+		t.Errorf("expected the hooks to run in order, every one of them, got %s", got)
+	}
+
+	if err = app.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected a second Shutdown to do nothing, got %v", err)
+	}
+	if len(ran) != 3 {
+		t.Errorf("hooks ran again on the second Shutdown: %v", ran)
+	}
+}
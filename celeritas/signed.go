@@ -0,0 +1,157 @@
+package celeritas
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// query parameters added to signed URLs
+const (
+	signatureParam = "signature"
+	expiresParam   = "expires"
+)
+
+// SignedURL returns path, with params added to its query string, signed
+// with the application's encryption key so that it cannot be altered.
+// With a positive ttl the URL also stops being valid after that long.
+// path may be absolute (https://example.com/unsubscribe); only its path
+// and query are signed.
+//
+//	link, err := app.SignedURL("/unsubscribe", url.Values{"email": {email}}, 7*24*time.Hour)
+func (c *Celeritas) SignedURL(path string, params url.Values, ttl time.Duration) (string, error) {
+	if c.EncryptionKey == "" {
+		return "", errors.New("signed URLs need an encryption key (KEY)")
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	query.Del(signatureParam)
+	query.Del(expiresParam)
+	if ttl > 0 {
+		query.Set(expiresParam, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	}
+
+	query.Set(signatureParam, c.signature(u.EscapedPath(), query))
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// SignedRouteURL is SignedURL for a named route (see URL), filling its
+// parameters from params
+func (c *Celeritas) SignedRouteURL(name string, ttl time.Duration, params ...interface{}) (string, error) {
+	path, err := c.BuildURL(name, params...)
+	if err != nil {
+		return "", err
+	}
+	return c.SignedURL(path, nil, ttl)
+}
+
+// ValidSignature reports whether the request's URL was produced by
+// SignedURL, has not been altered and has not expired
+func (c *Celeritas) ValidSignature(r *http.Request) bool {
+	if c.EncryptionKey == "" {
+		return false
+	}
+
+	query := r.URL.Query()
+	signature := query.Get(signatureParam)
+	if signature == "" {
+		return false
+	}
+	query.Del(signatureParam)
+
+	expected := c.signature(r.URL.EscapedPath(), query)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return false
+	}
+
+	if expires := query.Get(expiresParam); expires != "" {
+		at, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > at {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Signed answers 403 Forbidden unless the request's URL carries a valid,
+// unexpired signature (see SignedURL)
+//
+//	a.App.Routes.With(a.App.Signed).Get("/unsubscribe", a.Handlers.Unsubscribe)
+func (c *Celeritas) Signed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.ValidSignature(r) {
+			c.ErrorStatus(w, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// signature is the HMAC of the path and the sorted query string
+func (c *Celeritas) signature(path string, query url.Values) string {
+	mac := hmac.New(sha256.New, []byte(c.EncryptionKey))
+	mac.Write([]byte(path + "?" + query.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// templateSignedURL is signedURL() in templates: the ttl is a duration
+// such as "24h", or empty for a link which does not expire
+func (c *Celeritas) templateSignedURL(path, ttl string) (string, error) {
+	d, err := parseTTL(ttl)
+	if err != nil {
+		return "", err
+	}
+	return c.SignedURL(path, nil, d)
+}
+
+// templateSignedRoute is signedRoute() in templates
+func (c *Celeritas) templateSignedRoute(name, ttl string, params ...interface{}) (string, error) {
+	d, err := parseTTL(ttl)
+	if err != nil {
+		return "", err
+	}
+	return c.SignedRouteURL(name, d, params...)
+}
+
+// jetSignedURL is signedURL() in Jet templates. Jet ignores the error a
+// function returns, so it panics instead, which fails the template.
+func (c *Celeritas) jetSignedURL(path, ttl string) string {
+	return mustLink(c.templateSignedURL(path, ttl))
+}
+
+// jetSignedRoute is signedRoute() in Jet templates
+func (c *Celeritas) jetSignedRoute(name, ttl string, params ...interface{}) string {
+	return mustLink(c.templateSignedRoute(name, ttl, params...))
+}
+
+func mustLink(link string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return link
+}
+
+func parseTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(ttl)
+}
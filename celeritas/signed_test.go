@@ -0,0 +1,103 @@
+package celeritas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCeleritas_SignedURL(t *testing.T) {
+	c := &Celeritas{EncryptionKey: "7zllP1TbvJv99l1xRJfHVtxff7ZfdX9d"}
+	handler := c.Signed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	signed, err := c.SignedURL("https://example.com/unsubscribe?list=news", url.Values{"email": {"me@here.com"}}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(signed, "https://example.com/unsubscribe?") {
+		t.Fatalf("unexpected signed URL %q", signed)
+	}
+
+	// signed properly, but an hour ago with a minute to live
+	past := url.Values{expiresParam: {strconv.FormatInt(time.Now().Add(-59*time.Minute).Unix(), 10)}}
+	past.Set(signatureParam, c.signature("/download", past))
+	expired := "/download?" + past.Encode()
+	forever, err := c.SignedURL("/download", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name   string
+		target string
+		status int
+	}{
+		{"valid", signed, http.StatusOK},
+		{"no expiry", forever, http.StatusOK},
+		{"tampered", strings.Replace(signed, "me%40here.com", "you%40here.com", 1), http.StatusForbidden},
+		{"other path", strings.Replace(signed, "/unsubscribe", "/delete", 1), http.StatusForbidden},
+		{"unsigned", "/download", http.StatusForbidden},
+		{"expired", expired, http.StatusForbidden},
+	}
+
+	for _, e := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", e.target, nil))
+		if w.Code != e.status {
+			t.Errorf("%s: expected %d, got %d", e.name, e.status, w.Code)
+		}
+	}
+
+	other := &Celeritas{EncryptionKey: "another-key-of-sixteen"}
+	if other.ValidSignature(httptest.NewRequest("GET", signed, nil)) {
+		t.Error("signature accepted with a different key")
+	}
+}
+
+func TestCeleritas_SignedTemplateFuncs(t *testing.T) {
+	views := fstest.MapFS{
+		"route.jet":         &fstest.MapFile{Data: []byte(`{{ signedRoute("download", "1h", 5) }}`)},
+		"url.jet":           &fstest.MapFile{Data: []byte(`{{ signedURL("/unsubscribe", "") }}`)},
+		"bad-ttl.jet":       &fstest.MapFile{Data: []byte(`[{{ signedURL("/unsubscribe", "soon") }}]`)},
+		"unknown.jet":       &fstest.MapFile{Data: []byte(`[{{ signedRoute("nope", "1h") }}]`)},
+		"route.page.tmpl":   &fstest.MapFile{Data: []byte(`{{ signedRoute "download" "1h" 5 }}`)},
+		"url.page.tmpl":     &fstest.MapFile{Data: []byte(`{{ signedURL "/unsubscribe" "" }}`)},
+		"bad-ttl.page.tmpl": &fstest.MapFile{Data: []byte(`[{{ signedURL "/unsubscribe" "soon" }}]`)},
+		"unknown.page.tmpl": &fstest.MapFile{Data: []byte(`[{{ signedRoute "nope" "1h" }}]`)},
+	}
+
+	app := newTemplateApp(t, false, views)
+	app.NameRoute("download", "/downloads/{id}")
+
+	// without a KEY nothing can be signed
+	for _, view := range []string{"url.jet", "url.page.tmpl"} {
+		if got, err := renderView(t, app, view); err == nil {
+			t.Errorf("%s: expected signing without a key to fail, got %q", view, got)
+		}
+	}
+
+	app.EncryptionKey = "7zllP1TbvJv99l1xRJfHVtxff7ZfdX9d"
+
+	for _, view := range []string{"route.jet", "route.page.tmpl", "url.jet", "url.page.tmpl"} {
+		got, err := renderView(t, app, view)
+		if err != nil {
+			t.Errorf("%s: %s", view, err)
+			continue
+		}
+		link := strings.ReplaceAll(got, "&amp;", "&")
+		if !app.ValidSignature(httptest.NewRequest("GET", link, nil)) {
+			t.Errorf("%s: template produced an invalid signed link %q", view, got)
+		}
+	}
+
+	for _, view := range []string{"bad-ttl.jet", "unknown.jet", "bad-ttl.page.tmpl", "unknown.page.tmpl"} {
+		if got, err := renderView(t, app, view); err == nil {
+			t.Errorf("%s: expected the template to fail, got %q", view, got)
+		}
+	}
+}
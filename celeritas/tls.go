@@ -0,0 +1,201 @@
+package celeritas
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// cipherSuiteID looks up a cipher suite by its standard name, e.g.
+// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. Insecure suites are not accepted.
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// tlsConfig builds the server's TLS configuration from c.Config.TLS
+func (c *Celeritas) tlsConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if c.Config.TLS.SelfSigned {
+		cert, err = c.selfSignedCertificate()
+	} else {
+		cert, err = tls.LoadX509KeyPair(c.Config.TLS.CertFile, c.Config.TLS.KeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	minVersion, ok := tlsVersions[c.Config.TLS.MinVersion]
+	if !ok {
+		minVersion = tls.VersionTLS12
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+	}
+
+	for _, name := range c.Config.TLS.CipherSuites {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %s", name)
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+
+	return cfg, nil
+}
+
+// selfSignedCertificate returns a self-signed certificate for development,
+// generating it on first use and caching it in tmp/tls so that browsers
+// only have to accept it once
+func (c *Celeritas) selfSignedCertificate() (tls.Certificate, error) {
+	dir := filepath.Join(c.RootPath, "tmp", "tls")
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err == nil {
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err == nil && time.Now().Add(24*time.Hour).Before(leaf.NotAfter) {
+			return cert, nil
+		}
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Celeritas development"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	for _, host := range []string{c.Config.ServerName, "localhost", "127.0.0.1", "::1"} {
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.LoadX509KeyPair(certFile, keyFile)
+}
+
+// redirectToHTTPS sends every request to the same path over HTTPS on
+// SERVER_NAME. The Host header is not used, since the client controls it.
+func (c *Celeritas) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := c.Config.ServerName
+	if host == "" {
+		host = "localhost"
+	}
+	if c.Config.Port != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(c.Config.Port))
+	}
+
+	target := "https://" + host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// startRedirectServer starts the plain HTTP listener which redirects to
+// HTTPS, reporting a failure to start on serverErrors
+func (c *Celeritas) startRedirectServer(serverErrors chan<- error) {
+	c.redirectServer = &http.Server{
+		Addr:              fmt.Sprintf(":%d", c.Config.TLS.RedirectPort),
+		ErrorLog:          c.ErrorLog,
+		Handler:           http.HandlerFunc(c.redirectToHTTPS),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		c.Logger.Info("redirecting http to https", "port", c.Config.TLS.RedirectPort)
+		err := c.redirectServer.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+}
+
+// HSTS tells browsers to only use HTTPS for this site. It is added to the
+// middleware stack automatically when SECURE is true.
+func (c *Celeritas) HSTS(next http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d", c.Config.TLS.HSTSMaxAge)
+	if c.Config.TLS.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if c.Config.TLS.HSTSPreload {
+		value += "; preload"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,72 @@
+package celeritas
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCeleritas_SelfSignedCertificate(t *testing.T) {
+	c := &Celeritas{
+		RootPath: t.TempDir(),
+		Config: Config{
+			ServerName: "myapp.test",
+			TLS:        TLSConfig{SelfSigned: true, MinVersion: "1.3"},
+		},
+	}
+
+	cfg, err := c.tlsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("expected TLS 1.3 minimum, got %x", cfg.MinVersion)
+	}
+
+	again, err := c.selfSignedCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cfg.Certificates[0].Certificate[0], again.Certificate[0]) {
+		t.Error("self-signed certificate was regenerated instead of reused from tmp/tls")
+	}
+}
+
+func TestCeleritas_HSTS(t *testing.T) {
+	c := &Celeritas{Config: Config{TLS: TLSConfig{HSTSMaxAge: 600, HSTSIncludeSubdomains: true}}}
+	handler := c.HSTS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("GET", "https://localhost/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=600; includeSubDomains" {
+		t.Errorf("unexpected HSTS header %q", got)
+	}
+
+	r = httptest.NewRequest("GET", "http://localhost/", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("HSTS header sent over plain HTTP: %q", got)
+	}
+}
+
+func TestCeleritas_RedirectToHTTPS(t *testing.T) {
+	c := &Celeritas{Config: Config{Port: 4443, ServerName: "myapp.test"}}
+
+	for _, host := range []string{"myapp.test:4000", "evil.example"} {
+		r := httptest.NewRequest("GET", "http://localhost:4000/users/login?next=%2F", nil)
+		r.Host = host
+		w := httptest.NewRecorder()
+		c.redirectToHTTPS(w, r)
+
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("%s: expected 301, got %d", host, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != "https://myapp.test:4443/users/login?next=%2F" {
+			t.Errorf("%s: unexpected redirect location %q", host, got)
+		}
+	}
+}
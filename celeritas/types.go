@@ -7,26 +7,12 @@ type initPaths struct {
 	folderNames []string
 }
 
-type cookieConfig struct {
-	name     string
-	lifetime string
-	persist  string
-	secure   string
-	domain   string
-}
-
-type databaseConfig struct {
-	dsn      string
-	database string
-}
-
+// Database holds the application's connection pools. Writer is the
+// primary database and Reader is the read replica, or the primary again
+// when no replica is configured. Pool is the same as Writer.
 type Database struct {
 	DataType string
 	Pool     *sql.DB
-}
-
-type redisConig struct {
-	host     string
-	password string
-	prefix   string
+	Writer   *sql.DB
+	Reader   *sql.DB
 }
@@ -0,0 +1,181 @@
+package celeritas
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// NameRoute gives the route registered at pattern a name, by which URL,
+// and route() in templates, build links to it. The pattern is the full
+// path, including the prefix of any group or mounted router.
+//
+//	a.App.Routes.Get("/users/{id}", a.Handlers.ShowUser)
+//	a.App.NameRoute("user.show", "/users/{id}")
+func (c *Celeritas) NameRoute(name, pattern string) {
+	c.routeNamesMu.Lock()
+	defer c.routeNamesMu.Unlock()
+
+	if c.routeNames == nil {
+		c.routeNames = make(map[string]string)
+	}
+	if existing, ok := c.routeNames[name]; ok && existing != pattern {
+		c.routeError(fmt.Errorf("route name %q is used for both %s and %s", name, existing, pattern))
+	}
+	c.routeNames[name] = pattern
+}
+
+// URL returns the path of the named route, filling its parameters, in
+// order, from params. A final url.Values, map[string]string or
+// map[string]interface{} is added as the query string.
+//
+//	app.URL("user.show", 42)                                  // /users/42
+//	app.URL("users.index", url.Values{"page": {"2"}})         // /users?page=2
+//
+// An unknown name, or missing or surplus parameters, panic in debug mode
+// so that broken links are noticed straight away; otherwise the error is
+// logged and "#" is returned. Use BuildURL to handle the error yourself.
+func (c *Celeritas) URL(name string, params ...interface{}) string {
+	u, err := c.BuildURL(name, params...)
+	if err != nil {
+		c.routeError(err)
+		return "#"
+	}
+	return u
+}
+
+// routeError panics in debug mode and logs otherwise
+func (c *Celeritas) routeError(err error) {
+	if c.Debug {
+		panic(err)
+	}
+	if c.Logger != nil {
+		c.Logger.Error("named route", "error", err)
+	}
+}
+
+// templateURL is route() for Go templates, which report a failure as an
+// error in debug mode
+func (c *Celeritas) templateURL(name string, params ...interface{}) (string, error) {
+	u, err := c.BuildURL(name, params...)
+	if err != nil {
+		if c.Debug {
+			return "", err
+		}
+		c.routeError(err)
+		return "#", nil
+	}
+	return u, nil
+}
+
+// BuildURL is URL returning an error rather than panicking or logging
+func (c *Celeritas) BuildURL(name string, params ...interface{}) (string, error) {
+	c.routeNamesMu.RLock()
+	pattern, ok := c.routeNames[name]
+	c.routeNamesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+
+	var query url.Values
+	if n := len(params); n > 0 {
+		if q, ok := queryValues(params[n-1]); ok {
+			query = q
+			params = params[:n-1]
+		}
+	}
+
+	path, err := fillPattern(pattern, params)
+	if err != nil {
+		return "", fmt.Errorf("route %q (%s): %w", name, pattern, err)
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// queryValues converts the kinds of value accepted as a query string
+func queryValues(v interface{}) (url.Values, bool) {
+	switch q := v.(type) {
+	case url.Values:
+		return q, true
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range q {
+			values.Set(key, value)
+		}
+		return values, true
+	case map[string]interface{}:
+		values := url.Values{}
+		for key, value := range q {
+			values.Set(key, fmt.Sprint(value))
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// fillPattern replaces the {param} and {param:regexp} placeholders of a
+// chi pattern, and a trailing *, with params
+func fillPattern(pattern string, params []interface{}) (string, error) {
+	var b strings.Builder
+	next := 0
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			// find the closing brace, allowing for braces in the regexp
+			depth, end := 0, -1
+			for j := i; j < len(pattern) && end < 0; j++ {
+				switch pattern[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+			}
+			if end < 0 {
+				return "", fmt.Errorf("unterminated parameter")
+			}
+
+			key, rexp, _ := strings.Cut(pattern[i+1:end], ":")
+			if next >= len(params) {
+				return "", fmt.Errorf("missing parameter %s", key)
+			}
+			value := fmt.Sprint(params[next])
+			next++
+
+			if rexp != "" {
+				re, err := regexp.Compile("^(?:" + rexp + ")$")
+				if err == nil && !re.MatchString(value) {
+					return "", fmt.Errorf("parameter %s: %q does not match %s", key, value, rexp)
+				}
+			}
+
+			b.WriteString(url.PathEscape(value))
+			i = end
+		case '*':
+			if next < len(params) {
+				segments := strings.Split(fmt.Sprint(params[next]), "/")
+				for k := range segments {
+					segments[k] = url.PathEscape(segments[k])
+				}
+				b.WriteString(strings.Join(segments, "/"))
+				next++
+			}
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	if next < len(params) {
+		return "", fmt.Errorf("%d parameters given, but only %d used", len(params), next)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,122 @@
+package celeritas
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCeleritas_URL(t *testing.T) {
+	c := &Celeritas{}
+	c.NameRoute("home", "/")
+	c.NameRoute("user.show", "/users/{id:[0-9]+}")
+	c.NameRoute("post.comment", "/posts/{slug}/comments/{id}")
+	c.NameRoute("public", "/public/*")
+
+	var tests = []struct {
+		name     string
+		route    string
+		params   []interface{}
+		expected string
+		err      bool
+	}{
+		{"plain", "home", nil, "/", false},
+		{"param", "user.show", []interface{}{42}, "/users/42", false},
+		{"params and query", "post.comment", []interface{}{"hello world", 7, url.Values{"page": {"2"}}}, "/posts/hello%20world/comments/7?page=2", false},
+		{"map query", "home", []interface{}{map[string]string{"q": "a&b"}}, "/?q=a%26b", false},
+		{"wildcard", "public", []interface{}{"css/app.css"}, "/public/css/app.css", false},
+		{"unknown name", "nope", nil, "", true},
+		{"missing param", "user.show", nil, "", true},
+		{"surplus param", "home", []interface{}{1}, "", true},
+		{"regexp mismatch", "user.show", []interface{}{"abc"}, "", true},
+	}
+
+	for _, e := range tests {
+		got, err := c.BuildURL(e.route, e.params...)
+		if e.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", e.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", e.name, err)
+		} else if got != e.expected {
+			t.Errorf("%s: expected %q, got %q", e.name, e.expected, got)
+		}
+	}
+
+	if got := c.URL("nope"); got != "#" {
+		t.Errorf("expected # for an unknown route outside debug mode, got %q", got)
+	}
+
+	c.Debug = true
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unknown route in debug mode")
+		}
+	}()
+	c.URL("nope")
+}
+
+// newTemplateApp returns an application whose renderer is wired up as New
+// does it, reading the given views
+func newTemplateApp(t *testing.T, debug bool, views fstest.MapFS) *Celeritas {
+	t.Helper()
+	app, err := NewApp(
+		WithRootPath(t.TempDir()),
+		WithConfig(&Config{Port: 4000, Renderer: "jet", Debug: debug, SessionType: "cookie", Cookie: CookieConfig{Name: "celeritas", Lifetime: 60}}),
+		WithViews(views),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+// renderView renders a view through app.Render, as a handler would
+func renderView(t *testing.T, app *Celeritas, view string) (string, error) {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/", nil)
+	ctx, err := app.Session.Load(r.Context(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	err = app.Render.Page(w, r.WithContext(ctx), view, nil, nil)
+	return w.Body.String(), err
+}
+
+func TestCeleritas_RouteTemplateFunc(t *testing.T) {
+	views := fstest.MapFS{
+		"ok.jet":            &fstest.MapFile{Data: []byte(`{{ route("user.show", 3) }}`)},
+		"unknown.jet":       &fstest.MapFile{Data: []byte(`[{{ route("nope") }}]`)},
+		"params.jet":        &fstest.MapFile{Data: []byte(`[{{ route("user.show") }}]`)},
+		"ok.page.tmpl":      &fstest.MapFile{Data: []byte(`{{ route "user.show" 3 }}`)},
+		"unknown.page.tmpl": &fstest.MapFile{Data: []byte(`[{{ route "nope" }}]`)},
+		"params.page.tmpl":  &fstest.MapFile{Data: []byte(`[{{ route "user.show" }}]`)},
+	}
+
+	for _, debug := range []bool{true, false} {
+		app := newTemplateApp(t, debug, views)
+		app.NameRoute("user.show", "/users/{id}")
+
+		for _, view := range []string{"ok.jet", "ok.page.tmpl"} {
+			got, err := renderView(t, app, view)
+			if err != nil || got != "/users/3" {
+				t.Errorf("debug %v, %s: expected /users/3, got %q (%v)", debug, view, got, err)
+			}
+		}
+
+		for _, view := range []string{"unknown.jet", "params.jet", "unknown.page.tmpl", "params.page.tmpl"} {
+			got, err := renderView(t, app, view)
+			if debug && err == nil {
+				t.Errorf("%s: expected a bad route to fail the template in debug mode, got %q", view, got)
+			}
+			if !debug && (err != nil || got != "[#]") {
+				t.Errorf("%s: expected a bad route to render # outside debug mode, got %q (%v)", view, got, err)
+			}
+		}
+	}
+}
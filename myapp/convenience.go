@@ -2,14 +2,24 @@ package main
 
 import "net/http"
 
-func (a *application) get(s string, h http.HandlerFunc) {
+// get registers a GET route, optionally naming it for app.URL and route()
+func (a *application) get(s string, h http.HandlerFunc, name ...string) {
 	a.App.Routes.Get(s, h)
+	a.name(s, name)
 }
 
-func (a *application) post(s string, h http.HandlerFunc) {
+// post registers a POST route, optionally naming it for app.URL and route()
+func (a *application) post(s string, h http.HandlerFunc, name ...string) {
 	a.App.Routes.Post(s, h)
+	a.name(s, name)
 }
 
-// func (a *application) use(m ...func(http.Handler) http.Handler) {
-// 	a.App.Routes.Use(m...)
-// }
+func (a *application) name(s string, name []string) {
+	if len(name) > 0 {
+		a.App.NameRoute(name[0], s)
+	}
+}
+
+func (a *application) use(m ...func(http.Handler) http.Handler) {
+	a.App.Routes.Use(m...)
+}
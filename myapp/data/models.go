@@ -13,6 +13,11 @@ import (
 var db *sql.DB
 var upper db2.Session
 
+// reader may be used for queries which only read, so that they can be sent
+// to a read replica. Without a replica it is the same session as upper. A
+// replica can lag behind, so users and tokens are always read from upper.
+var reader db2.Session
+
 // Models is the wrapper for all database models
 type Models struct {
 	// any models inserted here (and in the New function)
@@ -21,23 +26,15 @@ type Models struct {
 	Tokens Token
 }
 
-// New initializes the models package for use
-func New(databasePool *sql.DB) Models {
+// New initializes the models package for use. An optional read replica
+// pool (celeritas' DB.Reader) is used for queries which only read.
+func New(databasePool *sql.DB, replicaPool ...*sql.DB) Models {
 	db = databasePool
 
-	switch os.Getenv("DATABASE_TYPE") {
-	case "mysql", "mariadb":
-		upper, _ = mysql.New(databasePool)
-	case "postgres", "postgresql":
-		upper, _ = postgresql.New(databasePool)
-	default:
-		// do nothing
-	}
-
-	if os.Getenv("DATABASE_TYPE") == "mysql" || os.Getenv("DATABASE_TYPE") == "mariadb" {
-		upper, _ = mysql.New(databasePool)
-	} else {
-		upper, _ = postgresql.New(databasePool)
+	upper = newSession(databasePool)
+	reader = upper
+	if len(replicaPool) > 0 && replicaPool[0] != nil && replicaPool[0] != databasePool {
+		reader = newSession(replicaPool[0])
 	}
 
 	return Models{
@@ -46,6 +43,22 @@ func New(databasePool *sql.DB) Models {
 	}
 }
 
+// newSession wraps a pool in the upper adapter for DATABASE_TYPE
+func newSession(pool *sql.DB) db2.Session {
+	var sess db2.Session
+
+	switch os.Getenv("DATABASE_TYPE") {
+	case "mysql", "mariadb":
+		sess, _ = mysql.New(pool)
+	case "sqlite", "sqlite3":
+		sess = newSQLiteSession(pool)
+	default:
+		sess, _ = postgresql.New(pool)
+	}
+
+	return sess
+}
+
 // getInsertID returns the integer value of a newly inserted id (using upper)
 func getInsertID(i db2.ID) int {
 	idType := fmt.Sprintf("%T", i)
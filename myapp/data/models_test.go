@@ -24,6 +24,12 @@ func TestNew(t *testing.T) {
 	if fmt.Sprintf("%T", m) != "data.Models" {
 		t.Error("Wrong type", fmt.Sprintf("%T", m))
 	}
+
+	_ = os.Setenv("DATABASE_TYPE", "sqlite")
+	m = New(fakeDB)
+	if fmt.Sprintf("%T", m) != "data.Models" {
+		t.Error("Wrong type", fmt.Sprintf("%T", m))
+	}
 }
 
 func TestGetInsertID(t *testing.T) {
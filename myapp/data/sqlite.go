@@ -0,0 +1,19 @@
+//go:build sqlite
+
+package data
+
+import (
+	"database/sql"
+
+	db2 "github.com/upper/db/v4"
+	"github.com/upper/db/v4/adapter/sqlite"
+)
+
+// newSQLiteSession wraps a pool in upper's sqlite adapter. The pool is
+// opened by celeritas with the pure Go sqlite driver, and the adapter only
+// needs it to build queries, but importing the adapter also links the cgo
+// github.com/mattn/go-sqlite3 driver, so it is only built with -tags sqlite.
+func newSQLiteSession(pool *sql.DB) db2.Session {
+	sess, _ := sqlite.New(pool)
+	return sess
+}
@@ -0,0 +1,15 @@
+//go:build !sqlite
+
+package data
+
+import (
+	"database/sql"
+
+	db2 "github.com/upper/db/v4"
+)
+
+// newSQLiteSession stops the application when it was built without
+// -tags sqlite (see sqlite.go)
+func newSQLiteSession(pool *sql.DB) db2.Session {
+	panic("DATABASE_TYPE is sqlite, but the application was built without -tags sqlite")
+}
@@ -0,0 +1,9 @@
+package main
+
+import "embed"
+
+// files are compiled into the binary, so that it can be deployed on its
+// own. With DEBUG=true the folders on disk are used instead.
+//
+//go:embed views public migrations
+var files embed.FS
@@ -1,6 +1,10 @@
 package handlers
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/leetrent/celeritas/render"
+)
 
 func (h *Handlers) UserLogin(w http.ResponseWriter, r *http.Request) {
 	err := h.App.Render.Page(w, r, "login", nil, nil)
@@ -12,7 +16,8 @@ func (h *Handlers) UserLogin(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) PostUserLogin(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		w.Write([]byte(err.Error()))
+		h.App.ErrorLog.Println(err)
+		h.App.Error500(w, r)
 		return
 	}
 
@@ -21,27 +26,35 @@ func (h *Handlers) PostUserLogin(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.Models.Users.GetByEmail(email)
 	if err != nil {
-		w.Write([]byte(err.Error()))
+		h.loginFailed(w, r, "Invalid credentials")
 		return
 	}
 
 	matches, err := user.PasswordMatches(password)
 	if err != nil {
-		w.Write([]byte("Error validating password"))
+		h.App.ErrorLog.Println(err)
+		h.loginFailed(w, r, "Error validating password")
 		return
 	}
 
 	if !matches {
-		w.Write([]byte("Invalid password"))
+		h.loginFailed(w, r, "Invalid credentials")
 		return
 	}
 
 	h.App.Session.Put(r.Context(), "userID", user.ID)
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	h.App.Flash(r.Context(), render.LevelFlash, "Logged in successfully")
+	http.Redirect(w, r, h.App.URL("home"), http.StatusSeeOther)
+}
+
+// loginFailed sends the user back to the login form with an error message
+func (h *Handlers) loginFailed(w http.ResponseWriter, r *http.Request, message string) {
+	h.App.Flash(r.Context(), render.LevelError, message)
+	http.Redirect(w, r, h.App.URL("login"), http.StatusSeeOther)
 }
 
 func (h *Handlers) UserLogout(w http.ResponseWriter, r *http.Request) {
 	h.App.Session.RenewToken(r.Context())
 	h.App.Session.Remove(r.Context(), "userID")
-	http.Redirect(w, r, "/users/login", http.StatusSeeOther)
+	http.Redirect(w, r, h.App.URL("login"), http.StatusSeeOther)
 }
@@ -1,21 +1,13 @@
 package handlers
 
 import (
-	"fmt"
-	"myapp/data"
 	"net/http"
 
-	"github.com/CloudyKit/jet/v6"
+	"github.com/leetrent/celeritas/render"
 )
 
 func (h *Handlers) Form(w http.ResponseWriter, r *http.Request) {
-	vars := make(jet.VarMap)
-	validator := h.App.Validator(nil)
-
-	vars.Set("validator", validator)
-	vars.Set("user", data.User{})
-
-	err := h.App.Render.Page(w, r, "form", vars, nil)
+	err := h.App.Render.Page(w, r, "form", nil, nil)
 	if err != nil {
 		h.App.ErrorLog.Println(err)
 	}
@@ -34,19 +26,10 @@ func (h *Handlers) PostForm(w http.ResponseWriter, r *http.Request) {
 	validator.Check(len(r.Form.Get("last_name")) > 1, "last_name", "Must be at least two characters")
 
 	if !validator.Valid() {
-		vars := make(jet.VarMap)
-		vars.Set("validator", validator)
-		var user data.User
-		user.FirstName = r.Form.Get("first_name")
-		user.LastName = r.Form.Get("last_name")
-		user.Email = r.Form.Get("email")
-		vars.Set("user", user)
-
-		if err := h.App.Render.Page(w, r, "form", vars, nil); err != nil {
-			h.App.ErrorLog.Println(err)
-			return
-		}
+		h.App.RedirectBackWithErrors(w, r, validator)
 		return
 	}
-	fmt.Fprint(w, "valid data")
+
+	h.App.Flash(r.Context(), render.LevelFlash, "Valid data")
+	http.Redirect(w, r, h.App.URL("form"), http.StatusSeeOther)
 }
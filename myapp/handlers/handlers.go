@@ -25,7 +25,7 @@ func (h *Handlers) Home(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GoPage(w http.ResponseWriter, r *http.Request) {
-	err := h.App.Render.GoPage(w, r, "home", nil)
+	err := h.App.Render.GoPage(w, r, "home", nil, nil)
 	if err != nil {
 		h.App.ErrorLog.Println("error rendering:", err)
 	}
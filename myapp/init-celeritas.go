@@ -23,7 +23,7 @@ func initApplication() *application {
 
 	// init celeritas
 	cel := &celeritas.Celeritas{}
-	err = cel.New(path)
+	err = cel.New(path, celeritas.WithFS(files))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -50,7 +50,7 @@ func initApplication() *application {
 
 	app.App.Routes = app.routes()
 
-	app.Models = data.New(app.App.DB.Pool)
+	app.Models = data.New(app.App.DB.Writer, app.App.DB.Reader)
 	myHandlers.Models = app.Models
 	app.Middleware.Models = app.Models
 
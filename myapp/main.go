@@ -1,6 +1,7 @@
 package main
 
 import (
+	"log"
 	"myapp/data"
 	"myapp/handlers"
 	"myapp/middleware"
@@ -17,5 +18,7 @@ type application struct {
 
 func main() {
 	c := initApplication()
-	c.App.ListenAndServe()
+	if err := c.App.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
 }
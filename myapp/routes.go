@@ -5,8 +5,10 @@ import (
 	"myapp/data"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/leetrent/celeritas"
 )
 
 func (a *application) routes() *chi.Mux {
@@ -17,23 +19,30 @@ func (a *application) routes() *chi.Mux {
 	//////////////////////////////////////////
 	// ADD ROUTES HERE
 	//////////////////////////////////////////
-	a.get("/", a.Handlers.Home)
-	a.App.Routes.Get("/go-page", a.Handlers.GoPage)
-	a.App.Routes.Get("/jet-page", a.Handlers.JetPage)
-	a.App.Routes.Get("/sessions", a.Handlers.SessionTest)
-	a.App.Routes.Get("/users/login", a.Handlers.UserLogin)
-	a.post("/users/login", a.Handlers.PostUserLogin)
-	a.App.Routes.Get("/users/logout", a.Handlers.UserLogout)
-
-	a.App.Routes.Get("/form", a.Handlers.Form)
-	a.App.Routes.Post("/form", a.Handlers.PostForm)
+	a.get("/", a.Handlers.Home, "home")
+	a.get("/go-page", a.Handlers.GoPage, "go-page")
+	a.get("/jet-page", a.Handlers.JetPage, "jet-page")
+	a.get("/sessions", a.Handlers.SessionTest, "sessions")
+	a.get("/users/login", a.Handlers.UserLogin, "login")
+	a.App.Routes.With(a.App.RateLimiter(celeritas.RateLimit{
+		Name:     "login",
+		Requests: 5,
+		Window:   time.Minute,
+	})).Post("/users/login", a.Handlers.PostUserLogin)
+	a.get("/users/logout", a.Handlers.UserLogout, "logout")
+
+	a.get("/form", a.Handlers.Form, "form")
+	a.post("/form", a.Handlers.PostForm)
 
 	///////////////////////////////////////////////
 	// TEST JSON, XML, File Download functionality
 	///////////////////////////////////////////////
-	a.get("/json", a.Handlers.JSON)
-	a.get("/xml", a.Handlers.XML)
-	a.get("/download-file", a.Handlers.DownloadFile)
+	// pages carry a fresh CSP nonce, so only responses which are the same
+	// for every request are tagged and answered with 304 Not Modified
+	a.App.Routes.With(a.App.ConditionalGet).Get("/json", a.Handlers.JSON)
+	a.App.NameRoute("json", "/json")
+	a.get("/xml", a.Handlers.XML, "xml")
+	a.get("/download-file", a.Handlers.DownloadFile, "download-file")
 
 	///////////////////////////////////////////////
 	// TEST ENCRYPTION, DECRYPTION FUNCTIONALITY
@@ -43,12 +52,20 @@ func (a *application) routes() *chi.Mux {
 	///////////////////////////////////////////////
 	// TEST REDIS CACHE FUNCTIONALITY
 	///////////////////////////////////////////////
-	a.get("/cache-test", a.Handlers.ShowCachePage)
-	a.post("/api/save-in-cache", a.Handlers.ShowCachePage)
-	a.post("/api/save-in-cache", a.Handlers.SaveInCache)
-	a.post("/api/get-from-cache", a.Handlers.GetFromCache)
-	a.post("/api/delete-from-cache", a.Handlers.DeleteFromCache)
-	a.post("/api/empty-cache", a.Handlers.EmptyCache)
+	a.get("/cache-test", a.Handlers.ShowCachePage, "cache-test")
+	a.App.Routes.Group(func(r chi.Router) {
+		r.Use(a.App.RateLimiter(celeritas.RateLimit{
+			Name:     "api",
+			Requests: 60,
+			Window:   time.Minute,
+			Strategy: celeritas.SlidingWindow,
+			Key:      celeritas.RateLimitByToken,
+		}))
+		r.Post("/api/save-in-cache", a.Handlers.SaveInCache)
+		r.Post("/api/get-from-cache", a.Handlers.GetFromCache)
+		r.Post("/api/delete-from-cache", a.Handlers.DeleteFromCache)
+		r.Post("/api/empty-cache", a.Handlers.EmptyCache)
+	})
 
 	//////////////////////////////////////////
 	// TEST DATABASE
@@ -105,6 +122,7 @@ func (a *application) routes() *chi.Mux {
 	///////////////////////////////////////////////////////////////////////////////
 	// TEST GET USER BY ID
 	///////////////////////////////////////////////////////////////////////////////
+	a.App.NameRoute("user.show", "/get-user/{id}")
 	a.App.Routes.Get("/get-user/{id}", func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
@@ -165,7 +183,7 @@ func (a *application) routes() *chi.Mux {
 	//////////////////////////////////////////
 	// STATIC ROUTES HERE
 	//////////////////////////////////////////
-	fileServer := http.FileServer(http.Dir("./public"))
+	fileServer := a.App.FileServer(http.FS(a.App.Public))
 	a.App.Routes.Handle("/public/*", http.StripPrefix("/public", fileServer))
 
 	return a.App.Routes